@@ -0,0 +1,136 @@
+// Package barcode renders IDs as machine-readable symbols in pure Go.
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Symbol is a barcode as grid of modules, true being dark. Linear barcodes consist of a single row.
+type Symbol struct {
+	Modules [][]bool
+	Quiet   int //light modules required around the symbol
+}
+
+// LinearHeight is the height of linear barcodes in modules when rendered
+const LinearHeight = 40
+
+// Linear reports whether the symbol is a one-dimensional barcode
+func (s *Symbol) Linear() bool {
+	return len(s.Modules) == 1
+}
+
+// Width returns the number of modules per row excluding the quiet zone
+func (s *Symbol) Width() int {
+	return len(s.Modules[0])
+}
+
+// Height returns the number of rendered rows excluding the quiet zone
+func (s *Symbol) Height() int {
+	if s.Linear() {
+		return LinearHeight
+	}
+	return len(s.Modules)
+}
+
+// Dark reports whether the module in column x and rendered row y, both excluding the quiet zone, is dark
+func (s *Symbol) Dark(x, y int) bool {
+	if s.Linear() {
+		y = 0
+	}
+	return s.Modules[y][x]
+}
+
+// SVG renders the symbol including its quiet zone, each module as square of scale pixels
+func (s *Symbol) SVG(scale int) string {
+	width, height := (s.Width()+2*s.Quiet)*scale, (s.Height()+2*s.Quiet)*scale
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		width, height, s.Width()+2*s.Quiet, s.Height()+2*s.Quiet)
+	fmt.Fprintf(&svg, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="`)
+	for y := 0; y < len(s.Modules); y++ {
+		rowHeight := 1
+		if s.Linear() {
+			rowHeight = LinearHeight
+		}
+		for x := 0; x < s.Width(); {
+			if !s.Modules[y][x] {
+				x++
+				continue
+			}
+			run := 0
+			for x+run < s.Width() && s.Modules[y][x+run] {
+				run++
+			}
+			fmt.Fprintf(&svg, "M%d %dh%dv%dh-%dz", x+s.Quiet, y+s.Quiet, run, rowHeight, run)
+			x += run
+		}
+	}
+	svg.WriteString(`"/></svg>`)
+	return svg.String()
+}
+
+// Image renders the symbol including its quiet zone, each module as square of scale pixels
+func (s *Symbol) Image(scale int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, (s.Width()+2*s.Quiet)*scale, (s.Height()+2*s.Quiet)*scale))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for y := 0; y < s.Height(); y++ {
+		for x := 0; x < s.Width(); x++ {
+			if !s.Dark(x, y) {
+				continue
+			}
+			for py := (y + s.Quiet) * scale; py < (y+s.Quiet+1)*scale; py++ {
+				for px := (x + s.Quiet) * scale; px < (x+s.Quiet+1)*scale; px++ {
+					img.SetGray(px, py, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG writes the image of the symbol as PNG
+func (s *Symbol) PNG(w io.Writer, scale int) error {
+	return png.Encode(w, s.Image(scale))
+}
+
+// BlocksLinearHeight is the height of linear barcodes in modules when rendered as blocks
+const BlocksLinearHeight = 8
+
+// Blocks renders the symbol including its quiet zone as block characters, two rows of modules per line.
+// Terminals with dark background need darkBackground to show the light modules as blocks.
+func (s *Symbol) Blocks(darkBackground bool) string {
+	width, height := s.Width()+2*s.Quiet, s.Height()+2*s.Quiet
+	if s.Linear() {
+		height = BlocksLinearHeight + 2*s.Quiet
+	}
+	filled := func(x, y int) bool {
+		x, y = x-s.Quiet, y-s.Quiet
+		dark := x >= 0 && y >= 0 && x < s.Width() && y < height-2*s.Quiet && s.Dark(x, y)
+		return dark != darkBackground
+	}
+	var blocks strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			upper, lower := filled(x, y), y+1 < height && filled(x, y+1)
+			switch {
+			case upper && lower:
+				blocks.WriteRune('█')
+			case upper:
+				blocks.WriteRune('▀')
+			case lower:
+				blocks.WriteRune('▄')
+			default:
+				blocks.WriteRune(' ')
+			}
+		}
+		blocks.WriteByte('\n')
+	}
+	return blocks.String()
+}
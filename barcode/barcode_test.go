@@ -0,0 +1,61 @@
+package barcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestSVG(t *testing.T) {
+	s, _ := Code128("72639D77LD")
+	svg := s.SVG(2)
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, fmt.Sprintf(`width="%d"`, 2*(s.Width()+20))) {
+		t.Errorf("unexpected SVG %s", svg)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	s, _ := QR("72639D77LD")
+	var buf bytes.Buffer
+	if err := s.PNG(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 3*29 || size.Y != 3*29 {
+		t.Errorf("unexpected size %v", size)
+	}
+	for y := 0; y < 29; y++ {
+		for x := 0; x < 29; x++ {
+			r, _, _, _ := img.At(3*x+1, 3*y+1).RGBA()
+			inside := x >= 4 && y >= 4 && x < 25 && y < 25
+			if dark := r == 0; dark != (inside && s.Dark(x-4, y-4)) {
+				t.Fatalf("unexpected pixel of module %d, %d", x, y)
+			}
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	s, _ := QR("72639D77LD")
+	lines := strings.Split(strings.TrimSuffix(s.Blocks(false), "\n"), "\n")
+	if len(lines) != (21+8+1)/2 || len([]rune(lines[0])) != 29 {
+		t.Fatalf("unexpected size of %d lines", len(lines))
+	}
+	if lines[0] != strings.Repeat(" ", 29) || !strings.HasPrefix(lines[2], "    █▀▀▀▀▀█") {
+		t.Errorf("unexpected blocks\n%s", strings.Join(lines, "\n"))
+	}
+	inverted := s.Blocks(true)
+	if !strings.HasPrefix(inverted, strings.Repeat("█", 29)) {
+		t.Errorf("expected light quiet zone as blocks\n%s", inverted)
+	}
+
+	linear, _ := Code39("72639D77LD")
+	if lines := strings.Split(strings.TrimSuffix(linear.Blocks(false), "\n"), "\n"); len(lines) != (BlocksLinearHeight+20)/2 {
+		t.Errorf("unexpected height %d of linear barcode", len(lines))
+	}
+}
@@ -0,0 +1,87 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// code128Patterns are the widths of bars and spaces of every symbol value, starting with a bar
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+func leadingDigits(s string) (n int) {
+	for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+		n++
+	}
+	return
+}
+
+// code128Values chooses the symbol values: digit pairs of the leading digits use code set C, the rest code set B
+func code128Values(data string) (values []int, err error) {
+	for i := 0; i < len(data); i++ {
+		if data[i] < 32 || data[i] > 126 {
+			return nil, fmt.Errorf("Character not encodable in Code 128: %q", data[i])
+		}
+	}
+	pairs := leadingDigits(data) / 2
+	if pairs < 2 {
+		pairs = 0 //switching code sets would not pay off
+	}
+	if pairs > 0 {
+		values = append(values, code128StartC)
+		for i := 0; i < pairs; i++ {
+			values = append(values, int(data[2*i]-'0')*10+int(data[2*i+1]-'0'))
+		}
+		if rest := data[2*pairs:]; rest != "" {
+			values = append(values, code128CodeB)
+		}
+	} else {
+		values = append(values, code128StartB)
+	}
+	for _, c := range []byte(data[2*pairs:]) {
+		values = append(values, int(c)-32)
+	}
+	check := values[0]
+	for i, v := range values[1:] {
+		check += (i + 1) * v
+	}
+	return append(values, check%103, code128Stop), nil
+}
+
+// Code128 encodes printable ASCII as Code 128 barcode
+func Code128(data string) (*Symbol, error) {
+	if data == "" {
+		return nil, fmt.Errorf("Nothing to encode")
+	}
+	values, err := code128Values(data)
+	if err != nil {
+		return nil, err
+	}
+	var row []bool
+	for _, v := range values {
+		for i, width := range code128Patterns[v] {
+			for w := '0'; w < width; w++ {
+				row = append(row, i%2 == 0)
+			}
+		}
+	}
+	return &Symbol{Modules: [][]bool{row}, Quiet: 10}, nil
+}
@@ -0,0 +1,103 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCode128Patterns(t *testing.T) {
+	seen := make(map[string]bool)
+	for v, p := range code128Patterns {
+		sum := 0
+		for _, w := range p {
+			sum += int(w - '0')
+		}
+		if v != code128Stop && sum != 11 || v == code128Stop && sum != 13 {
+			t.Errorf("pattern of value %d spans %d modules", v, sum)
+		}
+		if seen[p] {
+			t.Errorf("pattern of value %d is ambiguous", v)
+		}
+		seen[p] = true
+	}
+}
+
+// decodeCode128 reads the symbol back, independently of the encoder's choice of code sets
+func decodeCode128(row []bool) (string, error) {
+	var widths []byte
+	for i := 0; i < len(row); {
+		run := 0
+		for i+run < len(row) && row[i+run] == row[i] {
+			run++
+		}
+		widths = append(widths, byte('0'+run))
+		i += run
+	}
+	var values []int
+	for len(widths) > 0 {
+		n := 6
+		if len(widths) == 7 {
+			n = 7
+		}
+		value := -1
+		for v, p := range code128Patterns {
+			if p == string(widths[:n]) {
+				value = v
+			}
+		}
+		if value < 0 {
+			return "", fmt.Errorf("unknown pattern %s", widths[:n])
+		}
+		values = append(values, value)
+		widths = widths[n:]
+	}
+	if values[len(values)-1] != code128Stop {
+		return "", fmt.Errorf("missing stop")
+	}
+	check := values[0]
+	for i, v := range values[1 : len(values)-2] {
+		check += (i + 1) * v
+	}
+	if check%103 != values[len(values)-2] {
+		return "", fmt.Errorf("bad check symbol")
+	}
+	var data strings.Builder
+	codeC := values[0] == code128StartC
+	for _, v := range values[1 : len(values)-2] {
+		switch {
+		case codeC && v == code128CodeB:
+			codeC = false
+		case !codeC && v == code128CodeC:
+			codeC = true
+		case codeC:
+			fmt.Fprintf(&data, "%02d", v)
+		default:
+			data.WriteByte(byte(v + 32))
+		}
+	}
+	return data.String(), nil
+}
+
+func TestCode128(t *testing.T) {
+	for _, data := range []string{"72639D77LD", "72639Z77LD8", "52247CRMTY", "1234", "123", "A", "hello world!", "49999ZZZZZWZZZZFZZ5P"} {
+		s, err := Code128(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Linear() || s.Modules[0][0] != true || s.Modules[0][s.Width()-1] != true {
+			t.Errorf("%s: expected linear symbol starting and ending with a bar", data)
+		}
+		decoded, err := decodeCode128(s.Modules[0])
+		if err != nil || decoded != data {
+			t.Errorf("%s: decoded %q (%v)", data, decoded, err)
+		}
+	}
+	s, _ := Code128("72639D77LD")
+	if s.Width() != 11*(1+2+1+6+1)+13 {
+		t.Errorf("expected 2 digit pairs in code set C but got %d modules", s.Width())
+	}
+	if _, err := Code128("Ä"); err == nil {
+		t.Error("expected non-ASCII to be rejected")
+	}
+}
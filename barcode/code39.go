@@ -0,0 +1,48 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code39Chars are the characters of Code 39 in the order of their values, * is reserved for start and stop
+const code39Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-. $/+%*"
+
+// code39Patterns mark the wide elements of every character, alternating bars and spaces starting with a bar
+var code39Patterns = [...]string{
+	"000110100", "100100001", "001100001", "101100000", "000110001", "100110000", "001110000", "000100101", "100100100", "001100100",
+	"100001001", "001001001", "101001000", "000011001", "100011000", "001011000", "000001101", "100001100", "001001100", "000011100",
+	"100000011", "001000011", "101000010", "000010011", "100010010", "001010010", "000000111", "100000110", "001000110", "000010110",
+	"110000001", "011000001", "111000000", "010010001", "110010000", "011010000", "010000101", "110000100", "011000100", "010101000",
+	"010100010", "010001010", "000101010", "010010100",
+}
+
+// Code39Wide is the width of wide elements in modules, narrow ones span a single module
+const Code39Wide = 3
+
+// Code39 encodes digits, capital letters and -. $/+% as Code 39 barcode without optional check character
+func Code39(data string) (*Symbol, error) {
+	if data == "" {
+		return nil, fmt.Errorf("Nothing to encode")
+	}
+	var row []bool
+	for i, c := range "*" + data + "*" {
+		value := strings.IndexRune(code39Chars, c)
+		if value < 0 || c == '*' && i > 0 && i <= len(data) {
+			return nil, fmt.Errorf("Character not encodable in Code 39: %q", c)
+		}
+		if i > 0 {
+			row = append(row, false) //gap between characters
+		}
+		for j, wide := range code39Patterns[value] {
+			width := 1
+			if wide == '1' {
+				width = Code39Wide
+			}
+			for w := 0; w < width; w++ {
+				row = append(row, j%2 == 0)
+			}
+		}
+	}
+	return &Symbol{Modules: [][]bool{row}, Quiet: 10}, nil
+}
@@ -0,0 +1,70 @@
+package barcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCode39Patterns(t *testing.T) {
+	if len(code39Patterns) != len(code39Chars) {
+		t.Fatal("every character needs a pattern")
+	}
+	seen := make(map[string]bool)
+	for v, p := range code39Patterns {
+		wideBars, wideSpaces := 0, 0
+		for i, w := range p {
+			if w == '1' && i%2 == 0 {
+				wideBars++
+			} else if w == '1' {
+				wideSpaces++
+			}
+		}
+		//three of nine elements are wide, either two bars and a space or three spaces
+		if !(wideBars == 2 && wideSpaces == 1 || wideBars == 0 && wideSpaces == 3) || seen[p] {
+			t.Errorf("bad pattern %s of %c", p, code39Chars[v])
+		}
+		seen[p] = true
+	}
+}
+
+func TestCode39(t *testing.T) {
+	s, err := Code39("72639D77LD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	charWidth := 6 + 3*Code39Wide
+	if !s.Linear() || s.Width() != 12*charWidth+11 {
+		t.Errorf("unexpected width %d", s.Width())
+	}
+	//reads the characters back from the widths of the elements
+	var decoded strings.Builder
+	row := s.Modules[0]
+	for start := 0; start < len(row); start += charWidth + 1 {
+		var pattern strings.Builder
+		for x := start; x < start+charWidth; {
+			run := 1
+			for x+run < len(row) && row[x+run] == row[x] {
+				run++
+			}
+			if run == Code39Wide {
+				pattern.WriteByte('1')
+			} else {
+				pattern.WriteByte('0')
+			}
+			x += run
+		}
+		for v, p := range code39Patterns {
+			if p == pattern.String() {
+				decoded.WriteByte(code39Chars[v])
+			}
+		}
+	}
+	if decoded.String() != "*72639D77LD*" {
+		t.Errorf("decoded %s", decoded.String())
+	}
+	for _, bad := range []string{"", "72639d77ld", "7*2"} {
+		if _, err := Code39(bad); err == nil {
+			t.Errorf("%q: expected failure", bad)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// qrVersion describes the capacity of a QR code version at error correction level M
+type qrVersion struct {
+	blocks      int //number of blocks, all of the same size
+	dataPerBlk  int //data codewords per block
+	checkPerBlk int //error correction codewords per block
+}
+
+// qrVersions holds versions 1 to 6 at level M, enough for IDs of any length
+var qrVersions = [...]qrVersion{
+	1: {1, 16, 10},
+	2: {1, 28, 16},
+	3: {1, 44, 26},
+	4: {2, 32, 18},
+	5: {2, 43, 24},
+	6: {4, 27, 16},
+}
+
+// QRMaxVersion is the largest QR code version generated
+const QRMaxVersion = len(qrVersions) - 1
+
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+var gf256Exp, gf256Log [256]int
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gf256Exp[i] = x
+		gf256Log[x] = i
+		x <<= 1
+		if x > 255 {
+			x ^= 0x11d
+		}
+	}
+	gf256Exp[255] = gf256Exp[0]
+}
+
+func gf256Mul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf256Exp[(gf256Log[a]+gf256Log[b])%255]
+}
+
+// reedSolomon returns the n error correction codewords of the data
+func reedSolomon(data []byte, n int) []byte {
+	//generator polynomial (x - α^0)(x - α^1)...(x - α^(n-1)), highest coefficient omitted
+	generator := make([]int, n)
+	generator[n-1] = 1
+	for i := 0; i < n; i++ {
+		root := gf256Exp[i]
+		for j := 0; j < n; j++ {
+			generator[j] = gf256Mul(generator[j], root)
+			if j+1 < n {
+				generator[j] ^= generator[j+1]
+			}
+		}
+	}
+	remainder := make([]int, n)
+	for _, b := range data {
+		factor := int(b) ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[n-1] = 0
+		for j := range remainder {
+			remainder[j] ^= gf256Mul(generator[j], factor)
+		}
+	}
+	check := make([]byte, n)
+	for i, r := range remainder {
+		check[i] = byte(r)
+	}
+	return check
+}
+
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (b *bitBuffer) append(value int, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if value>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+// qrSegment encodes the data in alphanumeric mode if possible or else in byte mode
+func qrSegment(data string) *bitBuffer {
+	var b bitBuffer
+	alphanumeric := true
+	for _, c := range data {
+		alphanumeric = alphanumeric && strings.ContainsRune(qrAlphanumeric, c)
+	}
+	if !alphanumeric {
+		b.append(0b0100, 4)
+		b.append(len(data), 8)
+		for i := 0; i < len(data); i++ {
+			b.append(int(data[i]), 8)
+		}
+		return &b
+	}
+	b.append(0b0010, 4)
+	b.append(len(data), 9)
+	for i := 0; i+1 < len(data); i += 2 {
+		b.append(45*strings.IndexByte(qrAlphanumeric, data[i])+strings.IndexByte(qrAlphanumeric, data[i+1]), 11)
+	}
+	if len(data)%2 == 1 {
+		b.append(strings.IndexByte(qrAlphanumeric, data[len(data)-1]), 6)
+	}
+	return &b
+}
+
+// qrCodewords returns the data codewords of the smallest fitting version and its error correction codewords, interleaved
+func qrCodewords(data string) (version int, codewords []byte, err error) {
+	segment := qrSegment(data)
+	for version = 1; version <= QRMaxVersion; version++ {
+		if capacity := qrVersions[version].blocks * qrVersions[version].dataPerBlk * 8; segment.n <= capacity {
+			break
+		}
+	}
+	if version > QRMaxVersion || len(data) > 255 {
+		return 0, nil, fmt.Errorf("Too long for QR code version %d: %q", QRMaxVersion, data)
+	}
+	v := qrVersions[version]
+	capacity := v.blocks * v.dataPerBlk * 8
+	terminator := capacity - segment.n
+	if terminator > 4 {
+		terminator = 4
+	}
+	segment.append(0, terminator)
+	if segment.n%8 != 0 {
+		segment.append(0, 8-segment.n%8)
+	}
+	for pad := 0xec; segment.n < capacity; pad ^= 0xec ^ 0x11 {
+		segment.append(pad, 8)
+	}
+
+	var blocks, checks [][]byte
+	for i := 0; i < v.blocks; i++ {
+		block := segment.bytes[i*v.dataPerBlk : (i+1)*v.dataPerBlk]
+		blocks = append(blocks, block)
+		checks = append(checks, reedSolomon(block, v.checkPerBlk))
+	}
+	for _, group := range [][][]byte{blocks, checks} {
+		for i := range group[0] {
+			for _, block := range group {
+				codewords = append(codewords, block[i])
+			}
+		}
+	}
+	return version, codewords, nil
+}
+
+// qrMask reports whether mask pattern m inverts the module in column x and row y
+func qrMask(m, x, y int) bool {
+	switch m {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	}
+	return ((x+y)%2+x*y%3)%2 == 0
+}
+
+// qrFormatBits returns the BCH protected format information of level M and the mask pattern
+func qrFormatBits(mask int) int {
+	const levelM = 0b00
+	data := levelM<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+type qrMatrix struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+func newQRMatrix(version int) *qrMatrix {
+	m := &qrMatrix{size: 17 + 4*version}
+	for i := 0; i < m.size; i++ {
+		m.modules = append(m.modules, make([]bool, m.size))
+		m.function = append(m.function, make([]bool, m.size))
+	}
+	for i := 0; i < m.size; i++ {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+	m.finder(3, 3)
+	m.finder(m.size-4, 3)
+	m.finder(3, m.size-4)
+	if version > 1 {
+		//versions up to 6 have a single alignment pattern
+		c := 4*version + 10
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				m.set(c+dx, c+dy, max(abs(dx), abs(dy)) != 1)
+			}
+		}
+	}
+	m.format(0) //reserves the format areas
+	return m
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// set places a function module in column x and row y
+func (m *qrMatrix) set(x, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.function[y][x] = true
+}
+
+// finder draws the finder pattern centered at x, y including its separator
+func (m *qrMatrix) finder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= m.size || y >= m.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			m.set(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+// format draws both copies of the format information and the dark module
+func (m *qrMatrix) format(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+	for i := 0; i <= 5; i++ {
+		m.set(8, i, bit(i))
+	}
+	m.set(8, 7, bit(6))
+	m.set(8, 8, bit(7))
+	m.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		m.set(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.set(8, m.size-15+i, bit(i))
+	}
+	m.set(8, m.size-8, true)
+}
+
+// place fills the non-function modules with the codewords in the zigzag order, two columns at a time from the bottom right
+func (m *qrMatrix) place(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 //skips the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if m.function[y][x] {
+					continue
+				}
+				m.modules[y][x] = i < len(codewords)*8 && codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (m *qrMatrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if !m.function[y][x] && qrMask(mask, x, y) {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty rates how hard the symbol is to scan, the mask with the lowest penalty is used
+func (m *qrMatrix) penalty() (score int) {
+	finderLike := []string{"10111010000", "00001011101"}
+	dark := 0
+	for a := 0; a < m.size; a++ {
+		var row, column strings.Builder
+		for b := 0; b < m.size; b++ {
+			for _, line := range []*strings.Builder{&row, &column} {
+				x, y := b, a
+				if line == &column {
+					x, y = a, b
+				}
+				if m.modules[y][x] {
+					line.WriteByte('1')
+				} else {
+					line.WriteByte('0')
+				}
+			}
+			if m.modules[a][b] {
+				dark++
+			}
+			if a+1 < m.size && b+1 < m.size {
+				c := m.modules[a][b]
+				if m.modules[a][b+1] == c && m.modules[a+1][b] == c && m.modules[a+1][b+1] == c {
+					score += 3
+				}
+			}
+		}
+		for _, line := range []string{row.String(), column.String()} {
+			for i := 0; i < len(line); {
+				run := 1
+				for i+run < len(line) && line[i+run] == line[i] {
+					run++
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				i += run
+			}
+			for _, pattern := range finderLike {
+				score += 40 * strings.Count(line, pattern)
+			}
+		}
+	}
+	deviation := abs(dark*100/(m.size*m.size) - 50)
+	return score + deviation/5*10
+}
+
+// QR encodes the data as QR code of version 1 to 6 at error correction level M,
+// choosing alphanumeric mode for digits and capital letters
+func QR(data string) (*Symbol, error) {
+	if data == "" {
+		return nil, fmt.Errorf("Nothing to encode")
+	}
+	version, codewords, err := qrCodewords(data)
+	if err != nil {
+		return nil, err
+	}
+	var best *qrMatrix
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		m := newQRMatrix(version)
+		m.place(codewords)
+		m.applyMask(mask)
+		m.format(mask)
+		if p := m.penalty(); best == nil || p < bestPenalty {
+			best, bestPenalty = m, p
+		}
+	}
+	return &Symbol{Modules: best.modules, Quiet: 4}, nil
+}
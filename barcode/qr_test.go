@@ -0,0 +1,97 @@
+package barcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	//HELLO WORLD at version 1-M, the example of many QR code tutorials
+	version, codewords, err := qrCodewords("HELLO WORLD")
+	exp := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17, 196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if err != nil || version != 1 || !bytes.Equal(codewords, exp) {
+		t.Errorf("expected version 1 with %v but got %d with %v (%v)", exp, version, codewords, err)
+	}
+	if bits := qrFormatBits(0); bits != 0b101010000010010 {
+		t.Errorf("unexpected format bits %015b", bits)
+	}
+}
+
+// readQR reads the codewords back in zigzag order after removing the mask named by the format information
+func readQR(t *testing.T, s *Symbol) (version int, codewords []byte) {
+	size := len(s.Modules)
+	version = (size - 17) / 4
+	format := 0
+	for i, pos := range [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}} {
+		if s.Modules[pos[1]][pos[0]] {
+			format |= 1 << i
+		}
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("bad format information %015b", format)
+	}
+	function := newQRMatrix(version).function
+	var bits []bool
+	for pair := 0; pair < (size-1)/2; pair++ {
+		right := size - 1 - 2*pair
+		if right <= 6 {
+			right--
+		}
+		for step := 0; step < size; step++ {
+			y := size - 1 - step
+			if pair%2 == 1 {
+				y = step
+			}
+			for _, x := range []int{right, right - 1} {
+				if !function[y][x] {
+					bits = append(bits, s.Modules[y][x] != qrMask(mask, x, y))
+				}
+			}
+		}
+	}
+	for i := 0; i+8 <= len(bits); i += 8 {
+		b := byte(0)
+		for _, bit := range bits[i : i+8] {
+			b <<= 1
+			if bit {
+				b |= 1
+			}
+		}
+		codewords = append(codewords, b)
+	}
+	v := qrVersions[version]
+	return version, codewords[:v.blocks*(v.dataPerBlk+v.checkPerBlk)]
+}
+
+func TestQR(t *testing.T) {
+	for _, data := range []string{"72639D77LD", "72639Z77LD8", "49999ZZZZZWZZZZFZZ5P", "lower case 72639d77ld", strings.Repeat("72639Z77LD8", 9)} {
+		s, err := QR(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Linear() || s.Width() != len(s.Modules) || s.Quiet != 4 {
+			t.Errorf("%s: expected square symbol", data)
+		}
+		version, codewords := readQR(t, s)
+		expVersion, exp, _ := qrCodewords(data)
+		if version != expVersion || !bytes.Equal(codewords, exp) {
+			t.Errorf("%s: read codewords %v but expected %v", data, codewords, exp)
+		}
+	}
+	if s, _ := QR("72639D77LD"); s.Width() != 21 {
+		t.Errorf("expected version 1 for an ID but got %d modules", s.Width())
+	}
+	if s, _ := QR(strings.Repeat("72639Z77LD8", 7)); s.Width() != 33 {
+		t.Errorf("expected version 4 but got %d modules", s.Width())
+	}
+	if _, err := QR(strings.Repeat("A", 200)); err == nil {
+		t.Error("expected data exceeding version 6 to be rejected")
+	}
+}
@@ -0,0 +1,30 @@
+package barcode
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/n2code/ndocid"
+)
+
+// symbologyIdentifier is the AIM prefix some scanners send ahead of the payload, e.g. ]C0 for Code 128
+var symbologyIdentifier = regexp.MustCompile(`^\][A-Za-z][0-9A-Za-z]`)
+
+// Validate checks the payload of a scanned barcode and returns the canonical ID, keeping padding of fixed-length IDs,
+// and its value.
+// Symbology identifiers, surrounding whitespace and the start and stop characters of Code 39 are dropped.
+func Validate(payload string) (id string, value uint64, err error) {
+	payload = strings.TrimSpace(symbologyIdentifier.ReplaceAllString(strings.TrimSpace(payload), ""))
+	if len(payload) > 2 && strings.HasPrefix(payload, "*") && strings.HasSuffix(payload, "*") {
+		payload = payload[1 : len(payload)-1]
+	}
+	value, err, complete := ndocid.Decode(payload)
+	if err != nil {
+		return "", 0, err
+	}
+	if !complete {
+		return "", 0, fmt.Errorf("ID incomplete: %s", payload)
+	}
+	return ndocid.Canonical(payload), value, nil
+}
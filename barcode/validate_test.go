@@ -0,0 +1,26 @@
+package barcode
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	for _, payload := range []string{"72639D77LD", "]C072639D77LD\r\n", "]A0*72639D77LD*", " 72639d77ld ", "]Q172639D77LD"} {
+		id, value, err := Validate(payload)
+		if err != nil || id != "72639D77LD" || value != 1552572000 {
+			t.Errorf("%q: got %s %d (%v)", payload, id, value, err)
+		}
+	}
+	if id, _, err := Validate("]Q172639z77ld8"); err != nil || id != "72639Z77LD8" {
+		t.Errorf("expected canonical version 2 ID but got %s (%v)", id, err)
+	}
+	if id, _, err := Validate("]C072639d77ld22"); err != nil || id != "72639D77LD22" {
+		t.Errorf("expected padding to be kept but got %s (%v)", id, err)
+	}
+	if id, _, err := Validate("72G39D77LD22"); err != nil || id != "72639D77LD22" {
+		t.Errorf("expected aliases to be replaced but got %s (%v)", id, err)
+	}
+	for _, payload := range []string{"", "72639D77DL", "72639", "]C0"} {
+		if _, _, err := Validate(payload); err == nil {
+			t.Errorf("%q: expected failure", payload)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+type bucket struct {
+	Prefix   string  `json:"prefix"`
+	Count    uint64  `json:"count"`
+	Expected float64 `json:"expected"`
+}
+
+type prefixDistribution struct {
+	From             uint64   `json:"from"`
+	To               uint64   `json:"to"`
+	Samples          uint64   `json:"samples"`
+	Depth            int      `json:"depth"`
+	ChiSquare        float64  `json:"chi_square"`
+	DegreesOfFreedom int      `json:"degrees_of_freedom"`
+	PValue           float64  `json:"p_value"`
+	Unexpected       uint64   `json:"unexpected"` //samples with a prefix which should be impossible
+	Buckets          []bucket `json:"buckets"`
+}
+
+// expectedPrefixShares calculates the share of each possible prefix assuming uniformly distributed input.
+// The first five characters only depend on the 12 LSB, the sixth (master check) is assumed to be uniform in [1,29].
+func expectedPrefixShares(depth int) map[string]float64 {
+	shares := make(map[string]float64)
+	for x := uint64(0); x < 1<<12; x++ {
+		fixed := ndocid.EncodeUint64(x)[:5]
+		if depth <= 5 {
+			shares[fixed[:depth]] += 1.0 / (1 << 12)
+			continue
+		}
+		for mc := 1; mc <= 29; mc++ {
+			shares[fixed+string(ndocid.Alphabet[mc])] += 1.0 / (1 << 12) / 29
+		}
+	}
+	return shares
+}
+
+// parseRangeBound accepts a number or a date (2006-01-02) or date and time (2006-01-02T15:04:05) in local time
+func parseRangeBound(s string) (x uint64, isDate bool, err error) {
+	if x, err = strconv.ParseUint(s, 10, 64); err == nil {
+		return
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02T15:04:05"} {
+		if t, errDate := time.ParseInLocation(layout, s, time.Local); errDate == nil {
+			return uint64(t.Unix()), true, nil
+		}
+	}
+	err = fmt.Errorf("Bad range bound, neither number nor date: %s", s)
+	return
+}
+
+// parseRange accepts FROM..TO or FROM and TO as separate arguments, the upper bound of date ranges is exclusive
+func parseRange(args []string) (from, to uint64, err error) {
+	if len(args) == 1 {
+		i := strings.Index(args[0], "..")
+		if i < 0 {
+			err = fmt.Errorf("Bad range, expected FROM..TO: %s", args[0])
+			return
+		}
+		args = []string{args[0][:i], args[0][i+2:]}
+	}
+	if len(args) != 2 {
+		err = fmt.Errorf("Bad range, expected FROM..TO or FROM TO")
+		return
+	}
+	from, _, err = parseRangeBound(args[0])
+	if err != nil {
+		return
+	}
+	to, toIsDate, err := parseRangeBound(args[1])
+	if err != nil {
+		return
+	}
+	if toIsDate {
+		to--
+	}
+	if to < from {
+		err = fmt.Errorf("Empty range: %d..%d", from, to)
+	}
+	return
+}
+
+func distribution(args []string) {
+	flags := flag.NewFlagSet("distribution", flag.ExitOnError)
+	depth := flags.Int("depth", 3, "number of leading characters forming the prefix, 1 to 6")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of goroutines encoding in parallel")
+	format := flags.String("format", "text", "output format: text, csv or json")
+	samples := flags.Uint64("samples", 0, "number of random samples, overrides the 5 percent of random5percent")
+	seed := flags.Int64("seed", 1, "seed of the random samples")
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		exitWithUsageError("Bad number of arguments!")
+	}
+	if *depth < 1 || *depth > 6 {
+		exitWithUsageError("Prefix depth must be between 1 and 6!")
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+	if *format != "text" && *format != "csv" && *format != "json" {
+		exitWithUsageError("Output format must be text, csv or json!")
+	}
+	from, to, err := parseRange(flags.Args()[1:])
+	if err != nil {
+		sysErrLineOut(err)
+		os.Exit(1)
+	}
+
+	var n uint64
+	var sample func(rng *rand.Rand, i uint64) uint64
+	switch flags.Arg(0) {
+	case "all":
+		if to-from == math.MaxUint64 {
+			//the count would not fit into 64 bits, enumerating that many values is hopeless anyway
+			sysErrLineOut(fmt.Errorf("The full 64 bit range cannot be enumerated, use random5percent"))
+			os.Exit(1)
+		}
+		n = to - from + 1
+		sample = func(_ *rand.Rand, i uint64) uint64 { return from + i }
+	case "random5percent":
+		n = *samples
+		if n == 0 {
+			n = (to-from)/20 + 1
+		}
+		sample = func(rng *rand.Rand, _ uint64) uint64 {
+			span := to - from + 1
+			switch {
+			case span == 0:
+				return rng.Uint64() //full range
+			case span <= math.MaxInt64:
+				return from + uint64(rng.Int63n(int64(span)))
+			}
+			return from + rng.Uint64()%span
+		}
+	default:
+		exitWithUsageError("Bad argument!")
+	}
+
+	var done uint64
+	counts := make([]map[string]uint64, *workers)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(*seed + int64(w)))
+			local := make(map[string]uint64)
+			first, last := n/uint64(*workers)*uint64(w), n/uint64(*workers)*uint64(w+1)
+			if w == *workers-1 {
+				last = n
+			}
+			for i := first; i < last; i++ {
+				local[ndocid.EncodeUint64(sample(rng, i))[:*depth]]++
+				if i%4096 == 0 {
+					atomic.AddUint64(&done, 4096)
+				}
+			}
+			counts[w] = local
+		}(w)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	feedback := time.NewTicker(5 * time.Second)
+	defer feedback.Stop()
+	sysErrLineOut(fmt.Sprintf("Calculating prefix distribution of %d IDs in %d..%d...", n, from, to))
+wait:
+	for {
+		select {
+		case <-feedback.C:
+			sysErrLineOut(fmt.Sprintf("Approximately %d of %d done...", atomic.LoadUint64(&done), n))
+		case <-finished:
+			break wait
+		}
+	}
+
+	result := prefixDistribution{From: from, To: to, Samples: n, Depth: *depth}
+	observed := make(map[string]uint64)
+	for _, local := range counts {
+		for prefix, count := range local {
+			observed[prefix] += count
+		}
+	}
+	shares := expectedPrefixShares(*depth)
+	for prefix, share := range shares {
+		expected := share * float64(n)
+		count := observed[prefix]
+		result.Buckets = append(result.Buckets, bucket{prefix, count, expected})
+		result.ChiSquare += (float64(count) - expected) * (float64(count) - expected) / expected
+	}
+	for prefix, count := range observed {
+		if _, possible := shares[prefix]; !possible {
+			result.Unexpected += count
+		}
+	}
+	sort.Slice(result.Buckets, func(a, b int) bool { return result.Buckets[a].Prefix < result.Buckets[b].Prefix })
+	result.DegreesOfFreedom = len(shares) - 1
+	result.PValue = chiSquareP(result.ChiSquare, result.DegreesOfFreedom)
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	case "csv":
+		out := csv.NewWriter(os.Stdout)
+		out.Write([]string{"prefix", "count", "expected"})
+		for _, b := range result.Buckets {
+			out.Write([]string{b.Prefix, strconv.FormatUint(b.Count, 10), strconv.FormatFloat(b.Expected, 'f', 3, 64)})
+		}
+		out.Flush()
+		printSummary(os.Stderr, result)
+	default:
+		printSummary(os.Stdout, result)
+		fmt.Printf("\n%-8s %12s %14s %10s\n", "PREFIX", "COUNT", "EXPECTED", "DEVIATION")
+		for _, b := range result.Buckets {
+			fmt.Printf("%-8s %12d %14.1f %+9.2f%%\n", b.Prefix, b.Count, b.Expected, 100*(float64(b.Count)-b.Expected)/b.Expected)
+		}
+	}
+}
+
+func printSummary(out *os.File, d prefixDistribution) {
+	fmt.Fprintf(out, "Range: %d..%d (%s to %s)\n", d.From, d.To, time.Unix(int64(d.From), 0).Format(time.RFC3339), time.Unix(int64(d.To), 0).Format(time.RFC3339))
+	fmt.Fprintf(out, "Samples: %d, prefix depth: %d, possible prefixes: %d\n", d.Samples, d.Depth, len(d.Buckets))
+	fmt.Fprintf(out, "Chi-square: %.3f with %d degrees of freedom, p-value: %.4f\n", d.ChiSquare, d.DegreesOfFreedom, d.PValue)
+	if d.Unexpected > 0 {
+		fmt.Fprintf(out, "WARNING: %d samples with impossible prefixes\n", d.Unexpected)
+	}
+}
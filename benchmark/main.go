@@ -3,91 +3,33 @@ package main
 import (
 	"fmt"
 	"math"
-	"math/rand"
 	"os"
-	"strconv"
-	"strings"
-	"time"
 
 	"github.com/n2code/ndocid"
 )
 
 func main() {
-	switch len(os.Args) {
-	case 2:
-		switch os.Args[1] {
-		case "iterate":
-			for i := uint64(0); i < math.MaxUint64; i++ {
-				fmt.Println(ndocid.EncodeUint64(i))
-			}
-		default:
-			exitWithUsageError("Bad argument!")
+	if len(os.Args) < 2 {
+		exitWithUsageError("Bad number of arguments!")
+	}
+	switch os.Args[1] {
+	case "iterate":
+		if len(os.Args) != 2 {
+			exitWithUsageError("Bad number of arguments!")
 		}
-		os.Exit(0)
-	case 5:
-		switch os.Args[1] {
-		case "distribution":
-			from, errFrom := strconv.ParseUint(os.Args[3], 10, 64)
-			if errFrom != nil {
-				sysErrLineOut(errFrom)
-				os.Exit(1)
-			}
-			to, errTo := strconv.ParseUint(os.Args[4], 10, 64)
-			if errTo != nil {
-				sysErrLineOut(errTo)
-				os.Exit(1)
-			}
-
-			prefixes1 := make(map[string]int, 8)
-			prefixes2 := make(map[string]int, 8*8)
-			prefixes3 := make(map[string]int, 8*8*8)
-
-			recordedEncodeOf := func(in uint64) {
-				encoded := ndocid.EncodeUint64(in)
-				prefixes1[encoded[:1]]++
-				prefixes2[encoded[:2]]++
-				prefixes3[encoded[:3]]++
-			}
-
-			switch os.Args[2] {
-			case "all":
-				fmt.Println("Calculating full prefix distribution...")
-				feedback := time.Tick(5000 * time.Millisecond)
-				for i := from; i <= to; i++ {
-					select {
-					case <-feedback:
-						fmt.Printf("Currently at %d...\n", i)
-					default:
-						recordedEncodeOf(i)
-					}
-				}
-			case "random5percent":
-				fmt.Println("Calculating random prefix distribution...")
-				feedback := time.Tick(5000 * time.Millisecond)
-				for i := from; i <= from+(to-from+1)/20; i++ {
-					select {
-					case <-feedback:
-						fmt.Println("Still generating random IDs in range...")
-					default:
-						recordedEncodeOf(from + uint64(rand.Int63n(int64(to-from+1))))
-					}
-				}
-			default:
-				exitWithUsageError("Bad argument!")
-			}
-
-			printPrefixes := func(in map[string]int) {
-				raw := strings.ReplaceAll(fmt.Sprint(in), " ", "\n")
-				fmt.Println(raw[4 : len(raw)-1])
-			}
-			printPrefixes(prefixes1)
-			printPrefixes(prefixes2)
-			printPrefixes(prefixes3)
-			os.Exit(0)
+		for i := uint64(0); i < math.MaxUint64; i++ {
+			fmt.Println(ndocid.EncodeUint64(i))
 		}
+	case "distribution":
+		distribution(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	case "throughput":
+		throughput(os.Args[2:])
 	default:
-		exitWithUsageError("Bad number of arguments!")
+		exitWithUsageError("Bad argument!")
 	}
+	os.Exit(0)
 }
 
 func sysErrLineOut(msg interface{}) {
@@ -96,6 +38,15 @@ func sysErrLineOut(msg interface{}) {
 
 func exitWithUsageError(msg interface{}) {
 	sysErrLineOut(msg)
-	sysErrLineOut("\nUsage: benchmark iterate\n    OR benchmark distribution all|random5percent FROM TO\n       (e.g. benchmark distribution all 1000000 9999999)")
+	sysErrLineOut(`
+Usage: benchmark iterate
+    OR benchmark distribution [-depth N] [-workers N] [-format text|csv|json] [-samples N] [-seed N] all|random5percent RANGE
+       RANGE is either FROM..TO or FROM TO, bounds are numbers or dates in local time (2006-01-02 or 2006-01-02T15:04:05).
+       The upper bound of a range is inclusive for numbers and exclusive for dates.
+       (e.g. benchmark distribution all 1000000 9999999 or benchmark distribution -depth 4 all 2019-01-01..2020-01-01)
+    OR benchmark verify [-samples N] [-versions 1,2] [-json] [-seed N]
+       (detection rate of typos per error class, ID length and position)
+    OR benchmark throughput [-duration 2s | -count N] [-workers N] [-codecs v1,v2] [-ops encode,decode,validate] [-seed N]
+       (operations per second, time and allocations per operation of each codec side by side)`)
 	os.Exit(2)
 }
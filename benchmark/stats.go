@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+)
+
+// chiSquareP calculates the probability of a chi-square statistic at least as extreme as the given one
+func chiSquareP(chi2 float64, dof int) float64 {
+	if dof <= 0 {
+		return math.NaN()
+	}
+	return upperIncompleteGammaQ(float64(dof)/2, chi2/2)
+}
+
+// upperIncompleteGammaQ is the regularized upper incomplete gamma function Q(a, x)
+func upperIncompleteGammaQ(a, x float64) float64 {
+	switch {
+	case x <= 0:
+		return 1
+	case x < a+1:
+		return 1 - gammaSeries(a, x)
+	default:
+		return gammaContinuedFraction(a, x)
+	}
+}
+
+const (
+	gammaIterations = 1000
+	gammaEpsilon    = 1e-15
+	gammaTiny       = 1e-300
+)
+
+// gammaSeries evaluates P(a, x) by its series representation, converging quickly for x < a+1
+func gammaSeries(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	sum := 1 / a
+	term := sum
+	for n := 1; n < gammaIterations; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lgamma)
+}
+
+// gammaContinuedFraction evaluates Q(a, x) by Lentz's method, converging quickly for x >= a+1
+func gammaContinuedFraction(a, x float64) float64 {
+	lgamma, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / gammaTiny
+	d := 1 / b
+	h := d
+	for i := 1; i < gammaIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < gammaTiny {
+			d = gammaTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < gammaTiny {
+			c = gammaTiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lgamma) * h
+}
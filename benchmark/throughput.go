@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+// codec bundles the operations measured for one way of encoding IDs
+type codec struct {
+	name     string
+	encode   func(uint64) string
+	decode   func(string) bool
+	validate func(*ndocid.Validator, string) bool
+}
+
+var codecs = []codec{
+	{
+		name:   "v1",
+		encode: ndocid.V1.Encode,
+	},
+	{
+		name:   "v2",
+		encode: ndocid.V2.Encode,
+	},
+	{
+		name:   "words",
+		encode: ndocid.EncodeWords,
+		decode: decodeWords,
+		//words are typed as a whole, validating them equals decoding
+		validate: func(_ *ndocid.Validator, words string) bool { return decodeWords(words) },
+	},
+	{
+		name:   "digits",
+		encode: ndocid.EncodeDigits,
+		decode: decodeDigits,
+		//keys are pressed as a whole, validating them equals decoding
+		validate: func(_ *ndocid.Validator, digits string) bool { return decodeDigits(digits) },
+	},
+	{
+		name:   "sortable",
+		encode: ndocid.EncodeSortable,
+		decode: decodeSortable,
+		//the Validator knows the other layouts only
+		validate: func(_ *ndocid.Validator, id string) bool { return decodeSortable(id) },
+	},
+}
+
+func decodeWords(words string) bool {
+	_, err := ndocid.DecodeWords(words)
+	return err == nil
+}
+
+func decodeDigits(digits string) bool {
+	_, err, complete := ndocid.DecodeDigits(digits)
+	return err == nil && complete
+}
+
+func decodeSortable(id string) bool {
+	_, err, complete := ndocid.DecodeSortable(id)
+	return err == nil && complete
+}
+
+func decodeComplete(id string) bool {
+	_, err, complete := ndocid.Decode(id)
+	return err == nil && complete
+}
+
+func validateKeystrokes(v *ndocid.Validator, id string) bool {
+	v.Reset()
+	for _, char := range id {
+		v.Push(char)
+	}
+	return v.State().Status == ndocid.Complete
+}
+
+func init() {
+	for i := range codecs {
+		if codecs[i].decode == nil {
+			codecs[i].decode = decodeComplete
+		}
+		if codecs[i].validate == nil {
+			codecs[i].validate = validateKeystrokes
+		}
+	}
+}
+
+type measurement struct {
+	codec     string
+	operation string
+	ops       uint64
+	elapsed   time.Duration
+	allocs    uint64
+	bytes     uint64
+}
+
+// measure runs the operation on all workers until the count is reached or the duration is over
+func measure(workers int, count uint64, duration time.Duration, op func(worker int, i uint64) bool) (m measurement) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	var ops, failures uint64
+	deadline := time.Now().Add(duration)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				i := atomic.AddUint64(&ops, 1)
+				if count > 0 && i > count {
+					return
+				}
+				if !op(w, i) {
+					atomic.AddUint64(&failures, 1)
+				}
+				if count == 0 && i%1024 == 0 && time.Now().After(deadline) {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	m.elapsed = time.Since(start)
+
+	runtime.ReadMemStats(&after)
+	m.ops = atomic.LoadUint64(&ops)
+	if count > 0 {
+		m.ops = count //every worker counted one more operation before stopping
+	}
+	m.allocs = after.Mallocs - before.Mallocs
+	m.bytes = after.TotalAlloc - before.TotalAlloc
+	if failures > 0 {
+		sysErrLineOut(fmt.Sprintf("WARNING: %d operations failed", failures))
+	}
+	return
+}
+
+func throughput(args []string) {
+	flags := flag.NewFlagSet("throughput", flag.ExitOnError)
+	duration := flags.Duration("duration", 2*time.Second, "duration of each measurement, ignored if -count is set")
+	count := flags.Uint64("count", 0, "number of operations per measurement")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of goroutines running the operations")
+	codecList := flags.String("codecs", "v1,v2", "comma-separated list of codecs to compare, available: "+codecNames())
+	ops := flags.String("ops", "encode,decode,validate", "comma-separated list of operations to measure")
+	seed := flags.Int64("seed", 1, "seed of the random input values")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		exitWithUsageError("Leftover arguments after flags!")
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	//inputs are prepared upfront to measure nothing but the operations themselves
+	const inputs = 1 << 16
+	rng := rand.New(rand.NewSource(*seed))
+	values := make([]uint64, inputs)
+	for i := range values {
+		values[i] = rng.Uint64() >> uint(rng.Intn(64))
+	}
+
+	var results []measurement
+	for _, name := range strings.Split(*codecList, ",") {
+		c, ok := codecByName(name)
+		if !ok {
+			exitWithUsageError(fmt.Sprintf("Unknown codec: %s", name))
+		}
+		ids := make([]string, inputs)
+		for i, x := range values {
+			ids[i] = c.encode(x)
+		}
+		validators := make([]ndocid.Validator, *workers)
+		for _, op := range strings.Split(*ops, ",") {
+			var f func(w int, i uint64) bool
+			switch op {
+			case "encode":
+				f = func(_ int, i uint64) bool { return c.encode(values[i%inputs]) != "" }
+			case "decode":
+				f = func(_ int, i uint64) bool { return c.decode(ids[i%inputs]) }
+			case "validate":
+				f = func(w int, i uint64) bool { return c.validate(&validators[w], ids[i%inputs]) }
+			default:
+				exitWithUsageError(fmt.Sprintf("Unknown operation: %s", op))
+			}
+			m := measure(*workers, *count, *duration, f)
+			m.codec, m.operation = c.name, op
+			results = append(results, m)
+		}
+	}
+
+	fmt.Printf("%d worker(s), GOMAXPROCS %d, NS/OP is the time a single worker spends per operation\n\n", *workers, runtime.GOMAXPROCS(0))
+	fmt.Printf("%-10s %-10s %12s %14s %10s %12s %12s\n", "CODEC", "OPERATION", "OPS", "OPS/S", "NS/OP", "ALLOCS/OP", "BYTES/OP")
+	for _, m := range results {
+		perOp := func(total uint64) float64 { return float64(total) / float64(m.ops) }
+		fmt.Printf("%-10s %-10s %12d %14.0f %10.1f %12.2f %12.1f\n", m.codec, m.operation, m.ops,
+			float64(m.ops)/m.elapsed.Seconds(), float64(m.elapsed.Nanoseconds())*float64(*workers)/float64(m.ops),
+			perOp(m.allocs), perOp(m.bytes))
+	}
+}
+
+func codecByName(name string) (codec, bool) {
+	for _, c := range codecs {
+		if c.name == strings.ToLower(strings.TrimSpace(name)) {
+			return c, true
+		}
+	}
+	return codec{}, false
+}
+
+func codecNames() string {
+	names := make([]string, len(codecs))
+	for i, c := range codecs {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/n2code/ndocid"
+)
+
+var errorClasses = []string{"substitution", "adjacent-transposition", "jump-transposition", "twin", "insertion", "deletion", "confusable"}
+
+// tally counts the outcomes of decoding mistyped IDs
+type tally struct {
+	Total    int     `json:"total"`
+	Detected int     `json:"detected"`
+	Harmless int     `json:"harmless"` //decodes to the original value, e.g. an appended 2
+	Missed   int     `json:"missed"`
+	Rate     float64 `json:"rate"` //detected / (detected + missed)
+}
+
+func (t *tally) record(original uint64, mistyped string) {
+	t.Total++
+	value, err, complete := ndocid.Decode(mistyped)
+	switch {
+	case err != nil || !complete:
+		t.Detected++
+	case value == original:
+		t.Harmless++
+	default:
+		t.Missed++
+	}
+}
+
+func (t *tally) finish() {
+	t.Rate = 1
+	if t.Detected+t.Missed > 0 {
+		t.Rate = float64(t.Detected) / float64(t.Detected+t.Missed)
+	}
+}
+
+type verification struct {
+	Version    string                    `json:"version"`
+	Samples    int                       `json:"samples_per_length"`
+	ByClass    map[string]*tally         `json:"by_class"`
+	ByLength   map[int]map[string]*tally `json:"by_length"`
+	ByPosition map[int]map[string]*tally `json:"by_position"`
+}
+
+func newVerification(version ndocid.Version, samples int) *verification {
+	v := &verification{
+		Version:    version.String(),
+		Samples:    samples,
+		ByClass:    make(map[string]*tally),
+		ByLength:   make(map[int]map[string]*tally),
+		ByPosition: make(map[int]map[string]*tally),
+	}
+	for _, class := range errorClasses {
+		v.ByClass[class] = &tally{}
+	}
+	return v
+}
+
+func tallyOf(m map[int]map[string]*tally, key int, class string) *tally {
+	if m[key] == nil {
+		m[key] = make(map[string]*tally)
+	}
+	if m[key][class] == nil {
+		m[key][class] = &tally{}
+	}
+	return m[key][class]
+}
+
+// check enumerates every mistake of every error class for the given value
+func (v *verification) check(version ndocid.Version, x uint64) {
+	id := []rune(version.Encode(x))
+	record := func(class string, pos int, mistyped []rune) {
+		s := string(mistyped)
+		v.ByClass[class].record(x, s)
+		tallyOf(v.ByLength, len(id), class).record(x, s)
+		tallyOf(v.ByPosition, pos, class).record(x, s)
+	}
+	splice := func(from, to int, with ...rune) []rune {
+		m := append(append([]rune{}, id[:from]...), with...)
+		return append(m, id[to:]...)
+	}
+	for i, c := range id {
+		for _, r := range ndocid.Alphabet {
+			if r == c {
+				continue
+			}
+			record("substitution", i+1, splice(i, i+1, r))
+			if ndocid.Confusable(c, r) {
+				record("confusable", i+1, splice(i, i+1, r))
+			}
+			if i+1 < len(id) && id[i+1] == c {
+				record("twin", i+1, splice(i, i+2, r, r))
+			}
+		}
+		if i+1 < len(id) && id[i+1] != c {
+			record("adjacent-transposition", i+1, splice(i, i+2, id[i+1], c))
+		}
+		if i+2 < len(id) && id[i+2] != c {
+			record("jump-transposition", i+1, splice(i, i+3, id[i+2], id[i+1], c))
+		}
+		record("deletion", i+1, splice(i, i+1))
+	}
+	for i := 0; i <= len(id); i++ {
+		for _, r := range ndocid.Alphabet {
+			record("insertion", i+1, splice(i, i, r))
+		}
+	}
+}
+
+func (v *verification) finish() {
+	for _, t := range v.ByClass {
+		t.finish()
+	}
+	for _, m := range []map[int]map[string]*tally{v.ByLength, v.ByPosition} {
+		for _, classes := range m {
+			for _, t := range classes {
+				t.finish()
+			}
+		}
+	}
+}
+
+// sampleOfLength picks a random number whose version 1 encoding has the given length
+func sampleOfLength(rng *rand.Rand, length int) uint64 {
+	if length == 6 {
+		return uint64(rng.Int63n(1 << 12))
+	}
+	low := uint(12 + 5*(length-7))
+	x := rng.Uint64()>>low | 1
+	x <<= low
+	if high := low + 5; high < 64 {
+		x &= 1<<high - 1
+	}
+	return x | uint64(rng.Int63n(1<<low))
+}
+
+func verify(args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	samples := flags.Int("samples", 25, "number of random values per length of their version 1 encoding")
+	versions := flags.String("versions", "1", "comma-separated list of versions to check, e.g. 1,2")
+	asJSON := flags.Bool("json", false, "print JSON instead of tables")
+	seed := flags.Int64("seed", 1, "seed of the random sample")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		exitWithUsageError("Leftover arguments after flags!")
+	}
+
+	var results []*verification
+	for _, name := range strings.Split(*versions, ",") {
+		version, ok := parseVersion(name)
+		if !ok {
+			exitWithUsageError(fmt.Sprintf("Unknown version: %s", name))
+		}
+		rng := rand.New(rand.NewSource(*seed))
+		v := newVerification(version, *samples)
+		for length := 6; length <= 17; length++ {
+			for i := 0; i < *samples; i++ {
+				v.check(version, sampleOfLength(rng, length))
+			}
+		}
+		v.finish()
+		results = append(results, v)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(results)
+		return
+	}
+	for _, v := range results {
+		fmt.Printf("Error detection of %s IDs (%d random IDs per length of version 1 encoding):\n\n", v.Version, v.Samples)
+		fmt.Printf("%-24s %10s %10s %10s %10s %9s\n", "CLASS", "TOTAL", "DETECTED", "HARMLESS", "MISSED", "RATE")
+		for _, class := range errorClasses {
+			t := v.ByClass[class]
+			fmt.Printf("%-24s %10d %10d %10d %10d %8.4f%%\n", class, t.Total, t.Detected, t.Harmless, t.Missed, 100*t.Rate)
+		}
+		printRateTable("LENGTH", v.ByLength)
+		printRateTable("POSITION", v.ByPosition)
+		fmt.Println()
+	}
+}
+
+func printRateTable(key string, m map[int]map[string]*tally) {
+	fmt.Printf("\nDetection rate in %% by %s:\n%-8s", strings.ToLower(key), key)
+	for _, class := range errorClasses {
+		fmt.Printf(" %*s", columnWidth(class), class)
+	}
+	fmt.Println()
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		fmt.Printf("%-8d", k)
+		for _, class := range errorClasses {
+			if t, ok := m[k][class]; ok {
+				fmt.Printf(" %*.3f", columnWidth(class), 100*t.Rate)
+			} else {
+				fmt.Printf(" %*s", columnWidth(class), "-")
+			}
+		}
+		fmt.Println()
+	}
+}
+
+func columnWidth(class string) int {
+	if len(class) < 8 {
+		return 8
+	}
+	return len(class)
+}
+
+func parseVersion(name string) (ndocid.Version, bool) {
+	switch strings.TrimPrefix(strings.ToLower(name), "v") {
+	case "1":
+		return ndocid.V1, true
+	case "2":
+		return ndocid.V2, true
+	}
+	return 0, false
+}
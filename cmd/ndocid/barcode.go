@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/n2code/ndocid/barcode"
+)
+
+var symbologies = map[string]func(data string) (*barcode.Symbol, error){
+	"code128": barcode.Code128,
+	"code39":  barcode.Code39,
+	"qr":      barcode.QR,
+}
+
+// renderSymbol returns the symbol in the output format svg, png or text
+func renderSymbol(s *barcode.Symbol, format string, scale int, darkBackground bool) ([]byte, error) {
+	switch format {
+	case "svg":
+		return []byte(s.SVG(scale) + "\n"), nil
+	case "png":
+		var buf bytes.Buffer
+		err := s.PNG(&buf, scale)
+		return buf.Bytes(), err
+	}
+	return []byte(s.Blocks(darkBackground)), nil
+}
+
+func barcodeCommand(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("barcode", "ID", errOut)
+	symbology := flags.String("type", "code128", "`symbology`: code128, code39 or qr")
+	format := flags.String("format", "", "output `format`: svg, png or text (block characters), by default taken from the extension of -o or else text")
+	output := flags.String("o", "", "write the barcode to `file` instead of standard output")
+	scale := flags.Int("scale", 4, "size of a module in `pixels` for svg and png")
+	light := flags.Bool("light", false, "text for terminals with light background, dark modules become blocks")
+	scanned := flags.Bool("scanned", false, "validate ID as payload read by a scanner and print the canonical ID and its value instead")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 1 {
+		errOut("Exactly one ID expected (see ndocid barcode -h)")
+		return 2
+	}
+
+	id, value, err := barcode.Validate(flags.Arg(0))
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	if *scanned {
+		out("%s %d\n", id, value)
+		return 0
+	}
+
+	encode, ok := symbologies[*symbology]
+	if !ok {
+		errOut("Unknown symbology: %s", *symbology)
+		return 2
+	}
+	if *format == "" {
+		*format = "text"
+		if ext := strings.ToLower(filepath.Ext(*output)); ext == ".svg" || ext == ".png" {
+			*format = ext[1:]
+		}
+	}
+	if *format != "svg" && *format != "png" && *format != "text" {
+		errOut("Unknown format: %s", *format)
+		return 2
+	}
+	if *scale < 1 {
+		errOut("Scale must be positive")
+		return 2
+	}
+	symbol, err := encode(id)
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	rendered, err := renderSymbol(symbol, *format, *scale, !*light)
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	if *output == "" {
+		out("%s", rendered)
+		return 0
+	}
+	if err := os.WriteFile(*output, rendered, 0644); err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	return 0
+}
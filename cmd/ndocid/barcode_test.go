@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBarcodeCommand(t *testing.T) {
+	var text string
+	if status := barcodeCommand([]string{"-type", "qr", "72639d77ld"}, spyIntoString(&text), silentOut); status != 0 {
+		t.Fatalf("expected status 0 but got %d", status)
+	}
+	if !strings.HasPrefix(text, strings.Repeat("█", 29)+"\n") {
+		t.Errorf("expected QR code as blocks but got\n%s", text)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"id.svg", "id.png"} {
+		path := filepath.Join(dir, name)
+		if status := barcodeCommand([]string{"-o", path, "72639Z77LD8"}, silentOut, silentOut); status != 0 {
+			t.Errorf("%s: expected status 0 but got %d", name, status)
+		}
+		content, _ := os.ReadFile(path)
+		if !bytes.HasPrefix(content, []byte("<svg")) && !bytes.HasPrefix(content, []byte("\x89PNG")) {
+			t.Errorf("%s: unexpected content %q", name, content)
+		}
+	}
+
+	var scanned string
+	if status := barcodeCommand([]string{"-scanned", "]C072639D77LD"}, spyIntoString(&scanned), silentOut); status != 0 || scanned != "72639D77LD 1552572000\n" {
+		t.Errorf("unexpected validation %q with status %d", scanned, status)
+	}
+	if status := barcodeCommand([]string{"72639D77DL"}, silentOut, silentOut); status != 1 {
+		t.Errorf("expected invalid ID to fail with status 1 but got %d", status)
+	}
+	if status := barcodeCommand([]string{"-type", "ean", "72639D77LD"}, silentOut, silentOut); status != 2 {
+		t.Errorf("expected unknown symbology to fail with status 2 but got %d", status)
+	}
+}
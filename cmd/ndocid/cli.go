@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/n2code/ndocid"
@@ -20,6 +23,17 @@ type parameters struct {
 	now          bool
 	number       uint64
 	reverse      string
+	spoken       string
+	spell        string
+	fromWords    string
+	words        bool
+	fromDigits   string
+	digits       bool
+	sortable     bool
+	childOf      string
+	kind         string
+	length       int
+	version2     bool
 	flagsSet     int
 	leftoverArgs bool
 }
@@ -38,28 +52,175 @@ func run(p parameters, out outFunc, errOut outFunc) (status int) {
 		return 2
 	}
 
+	//in SPOKEN-MODE the spelling alphabet is understood, not output
+	outputs := 0
+	for _, set := range []bool{p.spell != "" && p.spoken == "", p.words, p.digits, p.sortable} {
+		if set {
+			outputs++
+		}
+	}
+	if outputs > 1 {
+		errOut(`Only one of -spell, -words, -digits and -sortable may be set %s`, seeUsage)
+		return 2
+	}
+	if p.length != 0 && (p.version2 || p.words || p.digits || p.sortable) {
+		errOut(`-len applies to version 1 IDs only and cannot be combined with -2, -words, -digits or -sortable %s`, seeUsage)
+		return 2
+	}
+	if p.length != 0 {
+		if err := ndocid.CheckLength(p.length); err != nil {
+			errOut("Bad -len: %s %s", err, seeUsage)
+			return 2
+		}
+	}
+	var alphabet *ndocid.SpellingAlphabet
+	if p.spell != "" {
+		var ok bool
+		if alphabet, ok = ndocid.SpellingAlphabets[p.spell]; !ok {
+			errOut("Unknown spelling alphabet %s, available are: %s", p.spell, strings.Join(spellingAlphabetNames(), ", "))
+			return 2
+		}
+	}
+	if p.spoken != "" {
+		var err error
+		if alphabet != nil {
+			p.reverse, err = alphabet.Transcribe(p.spoken)
+		} else {
+			p.reverse, alphabet, err = ndocid.TranscribeAny(p.spoken)
+		}
+		if err != nil {
+			out("INVALID\n")
+			errOut("%s", err)
+			return 1
+		}
+		verboseLineOut("Understood %s using spelling alphabet %s", p.reverse, alphabet.Name)
+	}
+
+	if p.childOf != "" {
+		kind := ndocid.Revision
+		if p.kind != "" {
+			var err error
+			if kind, err = ndocid.ParseChildKind(p.kind); err != nil {
+				errOut("%s", err)
+				return 2
+			}
+		}
+		parent, err := ndocid.ParseRef(p.childOf)
+		if err != nil {
+			errOut("%s", err)
+			return 1
+		}
+		verboseLineOut("Next %s of %s:", kind, parent)
+		out("%s", parent.Next(kind))
+		return 0
+	}
+
+	if p.fromWords != "" {
+		decoded, err := ndocid.DecodeWords(p.fromWords)
+		if err != nil {
+			out("INVALID\n")
+			errOut("%s", err)
+			return 1
+		}
+		version := ndocid.V1
+		if p.version2 {
+			version = ndocid.V2
+		}
+		out("OK\n%s\n", version.Encode(decoded))
+		verboseLineOut("Integer: %d", decoded)
+		verboseLineOut("Date: %s", time.Unix(int64(decoded), 0).Format(time.RFC1123Z))
+		return 0
+	}
+	if p.fromDigits != "" {
+		decoded, err, complete := ndocid.DecodeDigits(p.fromDigits)
+		switch {
+		case err != nil:
+			out("INVALID\n")
+			errOut("%s", err)
+			return 1
+		case !complete:
+			out("PARTIAL\n")
+			return 4
+		}
+		version := ndocid.V1
+		if p.version2 {
+			version = ndocid.V2
+		}
+		out("OK\n%s\n", version.Encode(decoded))
+		verboseLineOut("Integer: %d", decoded)
+		verboseLineOut("Date: %s", time.Unix(int64(decoded), 0).Format(time.RFC1123Z))
+		return 0
+	}
+
 	if p.reverse != "" {
-		decoded, err, complete := ndocid.Decode(p.reverse)
+		//the ID understood is shown after the result when spoken
+		understood := func(id string) {
+			if p.spoken != "" {
+				out("%s\n", id)
+			}
+		}
+		if strings.Contains(p.reverse, ndocid.RefSeparator) {
+			return checkRef(p.reverse, out, errOut)
+		}
+		//sortable IDs are told apart by their marker and converted to the other layout
+		sortable := ndocid.IsSortable(p.reverse)
+		decode := ndocid.Decode
+		if sortable {
+			decode = ndocid.DecodeSortable
+		} else if p.length != 0 {
+			decode = func(id string) (uint64, error, bool) { return ndocid.DecodeLength(id, p.length) }
+		}
+		decoded, err, complete := decode(p.reverse)
+		//the trace follows the status line and the lines promised by the other options
+		explain := func() {
+			if ndocid.Verbose && !sortable {
+				explainDecoding(ndocid.DecodeTrace(p.reverse))
+			}
+		}
 		if err != nil {
 			out("INVALID\n")
+			understood(p.reverse)
+			explain()
 			errOut("%s", err)
 			return 1
 		}
 		if complete {
 			out("OK\n")
+			if sortable {
+				understood(ndocid.EncodeSortable(decoded))
+				version := ndocid.V1
+				if p.version2 {
+					version = ndocid.V2
+				}
+				out("%s\n", version.Encode(decoded))
+			} else if p.spoken != "" {
+				understood(ndocid.VersionOf(p.reverse).Encode(decoded))
+			}
+			if p.words {
+				out("%s\n", ndocid.EncodeWords(decoded))
+			}
+			if p.digits {
+				out("%s\n", ndocid.EncodeDigits(decoded))
+			}
+			if p.sortable && !sortable {
+				out("%s\n", ndocid.EncodeSortable(decoded))
+			}
+			explain()
 			verboseLineOut("Integer: %d", decoded)
 			verboseLineOut("Date: %s", time.Unix(int64(decoded), 0).Format(time.RFC1123Z))
 			verboseLineOut("Bitstring: %b", decoded)
 		} else {
 			out("PARTIAL\n")
+			understood(p.reverse)
+			explain()
 			return 4
 		}
 	} else {
-		var encoded string
+		var number uint64
 		switch {
 		case p.date != "":
 			var err error
-			encoded, err = ndocid.EncodeDatetime(p.date)
+			number, err = ndocid.ParseDatetime(p.date)
 			if err != nil {
 				errOut("%s", err)
 				return 2
@@ -67,21 +228,157 @@ func run(p parameters, out outFunc, errOut outFunc) (status int) {
 		case p.now:
 			rightNow := time.Now()
 			verboseLineOut("Using current point in time: %s (unix time in seconds: %d)", rightNow.Format(time.RFC1123Z), rightNow.Unix())
-			encoded = ndocid.EncodeUint64(uint64(rightNow.Unix()))
+			number = uint64(rightNow.Unix())
 		case p.bitstring != "":
 			var err error
-			encoded, err = ndocid.EncodeBitstring(p.bitstring)
+			number, err = ndocid.ParseBitstring(p.bitstring)
 			if err != nil {
 				errOut("%s", err)
 				return 2
 			}
-		case p.reverse != "":
 		default:
-			encoded = ndocid.EncodeUint64(p.number)
+			number = p.number
 		}
 
-		verboseLineOut("Resulting encoded ID:")
-		out(encoded)
+		version := ndocid.V1
+		if p.version2 {
+			version = ndocid.V2
+		}
+		var encoded string
+		if p.length != 0 {
+			var err error
+			if encoded, err = ndocid.EncodeFixed(number, p.length); err != nil {
+				errOut("%s", err)
+				return 2
+			}
+		} else {
+			encoded = version.Encode(number)
+		}
+		if p.words {
+			verboseLineOut("Resulting encoded ID: %s", encoded)
+			verboseLineOut("As words:")
+			encoded = ndocid.EncodeWords(number)
+		} else if p.digits {
+			verboseLineOut("Resulting encoded ID: %s", encoded)
+			verboseLineOut("As digits:")
+			encoded = ndocid.EncodeDigits(number)
+		} else if p.sortable {
+			verboseLineOut("Resulting encoded ID: %s", encoded)
+			verboseLineOut("In sortable layout:")
+			encoded = ndocid.EncodeSortable(number)
+		} else if alphabet != nil {
+			verboseLineOut("Resulting encoded ID: %s", encoded)
+			verboseLineOut("Spelled using spelling alphabet %s:", alphabet.Name)
+			encoded, _ = alphabet.Spell(encoded)
+		} else {
+			verboseLineOut("Resulting encoded ID:")
+		}
+		out("%s", encoded)
+	}
+	return 0
+}
+
+// checkRef validates a reference to a revision or attachment of a document like an ID in REVERSING/CHECK-MODE
+func checkRef(s string, out outFunc, errOut outFunc) int {
+	ref, err := ndocid.ParseRef(s)
+	if err != nil {
+		out("INVALID\n")
+		errOut("%s", err)
+		return 1
+	}
+	out("OK\n")
+	for _, a := range append(ref.Ancestors(), ref)[1:] {
+		last := a.Children[len(a.Children)-1]
+		verboseLineOut("%s: %s %d", a, last.Kind, last.Index)
 	}
+	verboseLineOut("Document: %s", ref.ID)
 	return 0
 }
+
+func spellingAlphabetNames() (names []string) {
+	for name := range ndocid.SpellingAlphabets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func explainDecoding(t ndocid.Trace) {
+	verboseLineOut("Decoding %s:", t.Input)
+	verboseLineOut("  Mapping characters using custom Base32 alphabet:")
+	for _, c := range t.Chars {
+		if c.Value < 0 {
+			verboseLineOut("    #%-2d %c -> not part of alphabet", c.Position, c.Char)
+			continue
+		}
+		if t.Version == ndocid.V2 {
+			verboseLineOut("    #%-2d %c -> %2d", c.Position, c.Char, c.Value)
+		} else {
+			verboseLineOut("    #%-2d %c -> %2d (weight %d)", c.Position, c.Char, c.Value, c.Weight)
+		}
+	}
+	verboseLineOut("  Checking [F]ixed [P]art parity bits stored in FC (#1):")
+	for _, p := range t.Parity {
+		check := fmt.Sprintf("    %s := Even [P]arity bit for %2d LSB in #%d to #%d", p.Name, p.Bits, p.Covers[0], p.Covers[len(p.Covers)-1])
+		switch {
+		case !p.Evaluated:
+			verboseLineOut("%s: not evaluated", check)
+		case p.OK():
+			verboseLineOut("%s: expected %d, actual %d: OK", check, p.Expected, p.Actual)
+		default:
+			verboseLineOut("%s: expected %d, actual %d: FAILED at #%d", check, p.Expected, p.Actual, p.Position)
+		}
+	}
+	if t.Version == ndocid.V2 {
+		verboseLineOut("  Version 2 marker found in #6, checking Damm [C]heck characters:")
+		for _, d := range t.Damm {
+			if d.OK() {
+				verboseLineOut("    C in #%d: interim 0: OK", d.Position)
+			} else {
+				verboseLineOut("    C in #%d: interim %d instead of 0: FAILED", d.Position, d.Interim)
+			}
+		}
+		if len(t.Damm) == 0 {
+			verboseLineOut("    not evaluated")
+		}
+	} else {
+		verboseLineOut("  Checking [M]aster [C]heck digit:")
+		if t.Master.Evaluated {
+			verboseLineOut("    S := weighted sum 3*#1 + 1*#2 + 3*#3 + 1*#4 + ... : %d", t.Master.Sum)
+			if t.Master.OK() {
+				verboseLineOut("    S modulo 29: 0: OK")
+			} else {
+				verboseLineOut("    S modulo 29: %d: FAILED, off by %d (or %d short)", t.Master.Remainder, t.Master.Remainder, 29-t.Master.Remainder)
+			}
+		} else {
+			verboseLineOut("    not evaluated")
+		}
+	}
+	switch {
+	case t.Err != nil:
+		verboseLineOut("< Result: INVALID (%s)", t.Err)
+	case t.Complete:
+		verboseLineOut("< Result: OK (%d)", t.Value)
+	default:
+		verboseLineOut("< Result: PARTIAL")
+	}
+	if len(t.Suspects) > 0 {
+		marker := []rune(strings.Repeat(" ", len(t.Chars)))
+		for _, s := range t.Suspects {
+			marker[s-1] = '^'
+		}
+		verboseLineOut("Probable wrong character(s):")
+		verboseLineOut("  %s", t.Input)
+		verboseLineOut("  %s", strings.TrimRight(string(marker), " "))
+	}
+	if len(t.Corrections) > 0 {
+		verboseLineOut("Valid IDs one edit away:")
+		for _, c := range t.Corrections {
+			likely := ""
+			if c.Likely {
+				likely = ", likely"
+			}
+			verboseLineOut("  %s (%s at #%d%s)", c.ID, c.Kind, c.Position, likely)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+
+	"github.com/n2code/ndocid"
 )
 
 func silentOut(format string, msg ...interface{}) {
@@ -53,8 +56,14 @@ func TestBitstringEncoding(t *testing.T) {
 	assertSuccess(parameters{bitstring: "01011101 01110011 10010111 11010110", flagsSet: 1}, "^68495LTTOD$", t)
 }
 
+func TestVersion2Encoding(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, version2: true, flagsSet: 1}, "^72639Z77LD8$", t)
+	assertSuccess(parameters{bitstring: "01011101 01110011 10010111 11010110", version2: true, flagsSet: 1}, "^68495Z[[:alnum:]]{5}$", t)
+}
+
 func TestVerificationFull(t *testing.T) {
 	assertStatus(parameters{reverse: "68495LTTOD", flagsSet: 1}, 0, t)
+	assertStatus(parameters{reverse: "72639Z77DL8", flagsSet: 1}, 1, t)
 }
 
 func TestVerificationPartial(t *testing.T) {
@@ -84,3 +93,93 @@ func TestBadUsageBitstringTooLong(t *testing.T) {
 func TestBadUsageDateFormatInvalid(t *testing.T) {
 	assertStatus(parameters{date: "2021", flagsSet: 1}, 2, t)
 }
+
+func TestVerificationVerbose(t *testing.T) {
+	ndocid.Verbose = true
+	var explanation string
+	verboseLineOut = spyIntoString(&explanation)
+	defer func() {
+		ndocid.Verbose = false
+		verboseLineOut = func(format string, msg ...interface{}) {}
+	}()
+
+	assertStatus(parameters{reverse: "94785JBZOD", flagsSet: 1}, 1, t)
+	if !strings.Contains(explanation, "94875JBZOD (transposition at #3, likely)") {
+		t.Errorf("explanation lacks correction: %s", explanation)
+	}
+	assertStatus(parameters{reverse: "92332", flagsSet: 1}, 1, t)
+	assertStatus(parameters{reverse: "68495LTTOD", flagsSet: 1}, 0, t)
+	assertStatus(parameters{reverse: "72639Z77DL8", flagsSet: 1}, 1, t)
+
+	//the status stays the first line
+	for id, status := range map[string]string{"94785JBZOD": "INVALID", "94875JBZOD": "OK", "9487": "PARTIAL"} {
+		var combined string
+		verboseLineOut = func(format string, msg ...interface{}) { combined += fmt.Sprintf(format+"\n", msg...) }
+		run(parameters{reverse: id, flagsSet: 1}, spyIntoString(&combined), spyIntoString(new(string)))
+		if !strings.HasPrefix(combined, status+"\nDecoding "+id) {
+			t.Errorf("expected %s followed by the explanation but got %s", status, combined)
+		}
+	}
+}
+
+func TestSpelling(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, spell: "nato", flagsSet: 1}, "^seven two six three nine delta seven seven lima delta$", t)
+	assertSuccess(parameters{spoken: "seven two six three niner delta seven seven lima delta", flagsSet: 1}, "^OK\n72639D77LD\n$", t)
+	assertSuccess(parameters{spoken: "sieben zwei sechs drei neun Dora sieben sieben Ludwig Dora", spell: "din5009", flagsSet: 1}, "^OK\n72639D77LD\n$", t)
+	assertStatus(parameters{spoken: "seven two six three nine", flagsSet: 1}, 4, t)
+	assertStatus(parameters{spoken: "seven two six three nine delta seven seven delta lima", flagsSet: 1}, 1, t)
+	assertStatus(parameters{spoken: "seven two six three nine", spell: "din5009", flagsSet: 1}, 1, t)
+	assertStatus(parameters{number: 42, spell: "klingon", flagsSet: 1}, 2, t)
+}
+
+func TestWords(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, words: true, flagsSet: 1}, "^inch-metal-insect-island-audio$", t)
+	assertSuccess(parameters{reverse: "72639Z77LD8", words: true, flagsSet: 1}, "^OK\ninch-metal-insect-island-audio\n$", t)
+	assertSuccess(parameters{fromWords: "inch metal insect island audio", flagsSet: 1}, "^OK\n72639D77LD\n$", t)
+	assertSuccess(parameters{fromWords: "INC-MET-INS-ISL-AUD", version2: true, flagsSet: 1}, "^OK\n72639Z77LD8\n$", t)
+	assertStatus(parameters{fromWords: "inch metal island insect audio", flagsSet: 1}, 1, t)
+	assertStatus(parameters{number: 42, words: true, spell: "nato", flagsSet: 1}, 2, t)
+	assertSuccess(parameters{spoken: "seven two six three nine delta seven seven lima delta", words: true, flagsSet: 1}, "^OK\n72639D77LD\ninch-metal-insect-island-audio\n$", t)
+}
+
+func TestDigits(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, digits: true, flagsSet: 1}, "^7263956540973$", t)
+	assertSuccess(parameters{reverse: "72639Z77LD8", digits: true, flagsSet: 1}, "^OK\n7263956540973\n$", t)
+	assertSuccess(parameters{fromDigits: "7263956540973", version2: true, flagsSet: 1}, "^OK\n72639Z77LD8\n$", t)
+	assertStatus(parameters{fromDigits: "7263965540973", flagsSet: 1}, 1, t)
+	assertStatus(parameters{fromDigits: "726395", flagsSet: 1}, 4, t)
+	assertStatus(parameters{number: 42, words: true, digits: true, flagsSet: 1}, 2, t)
+}
+
+func TestSortable(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, sortable: true, flagsSet: 1}, "^Z2222223HANQM2V$", t)
+	assertSuccess(parameters{reverse: "72639D77LD", sortable: true, flagsSet: 1}, "^OK\nZ2222223HANQM2V\n$", t)
+	assertSuccess(parameters{reverse: "Z2222223HANQM2V", version2: true, flagsSet: 1}, "^OK\n72639Z77LD8\n$", t)
+	assertSuccess(parameters{spoken: "zulu two two two two two two three hotel alfa november quebec mike two victor", flagsSet: 1}, "^OK\nZ2222223HANQM2V\n72639D77LD\n$", t)
+	assertStatus(parameters{reverse: "Z2222223HANQM22", flagsSet: 1}, 1, t)
+	assertStatus(parameters{reverse: "Z2222223", flagsSet: 1}, 4, t)
+	assertStatus(parameters{number: 42, sortable: true, digits: true, flagsSet: 1}, 2, t)
+}
+
+func TestChildren(t *testing.T) {
+	assertSuccess(parameters{childOf: "96822L9IPD", flagsSet: 1}, "^96822L9IPD-R1N$", t)
+	assertSuccess(parameters{childOf: "96822L9IPD-R2C", flagsSet: 1}, "^96822L9IPD-R3A$", t)
+	assertSuccess(parameters{childOf: "96822l9ipd-r2c", kind: "attachment", flagsSet: 1}, "^96822L9IPD-R2C-A1V$", t)
+	assertStatus(parameters{childOf: "96822L9IPD-R2D", flagsSet: 1}, 1, t)
+	assertStatus(parameters{childOf: "96822L9IPD", kind: "draft", flagsSet: 1}, 2, t)
+	assertSuccess(parameters{reverse: "96822L9IPD-R2C-A1V", flagsSet: 1}, "^OK\n$", t)
+	assertStatus(parameters{reverse: "96822L9IPD-R2C-A1W", flagsSet: 1}, 1, t)
+}
+
+func TestLength(t *testing.T) {
+	assertSuccess(parameters{number: 1552572000, length: 12, flagsSet: 1}, "^72639D77LD22$", t)
+	assertStatus(parameters{number: 1552572000, length: 9, flagsSet: 1}, 2, t)
+	assertStatus(parameters{number: 1552572000, length: 12, version2: true, flagsSet: 1}, 2, t)
+	assertSuccess(parameters{reverse: "72639D77LD22", length: 12, flagsSet: 1}, "^OK\n$", t)
+	assertStatus(parameters{reverse: "72639D77LD", length: 12, flagsSet: 1}, 4, t)
+	assertStatus(parameters{reverse: "72639D77LD22", length: 10, flagsSet: 1}, 1, t)
+	assertStatus(parameters{reverse: "72639D77LD", length: -2, flagsSet: 1}, 2, t)
+	assertStatus(parameters{reverse: "72639D77LD", length: 18, flagsSet: 1}, 2, t)
+	assertStatus(parameters{number: 1552572000, length: 5, flagsSet: 1}, 2, t)
+	assertStatus(parameters{reverse: "72639Z77LD8", length: 11, flagsSet: 1}, 1, t)
+}
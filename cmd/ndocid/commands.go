@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// command is a subcommand invoked as "ndocid NAME [FLAGS] [ARGS]"
+type command struct {
+	run     func(args []string, out outFunc, errOut outFunc) (status int)
+	summary string
+}
+
+var commands = map[string]command{
+	"barcode": {barcodeCommand, "Render an ID as Code 128, Code 39 or QR code"},
+	"csv":     {csvCommand, "Encode, decode or validate a column of CSV or TSV data"},
+	"grep":    {grep, "Find valid IDs in files or standard input"},
+	"lint":    {lint, "Check IDs in the file names of a document tree"},
+	"labels":  {labels, "Print sheets of labels with fresh IDs and their barcodes as PDF"},
+	"links":   {links, "Check links between notes named by IDs"},
+	"lsp":     {lsp, "Check IDs in text files as language server speaking LSP over stdio"},
+	"render":  {render, "Render a document template with a freshly issued ID"},
+	"serve":   {serve, "Serve encoding, decoding and suggestions as HTTP/JSON API"},
+	"stamp":   {stamp, "Rename files using IDs derived from their modification times"},
+	"watch":   {watch, "Assign IDs to new files arriving in a folder"},
+}
+
+func commandNames() (names []string) {
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func commandUsage() string {
+	var usage strings.Builder
+	usage.WriteString("Commands (see ndocid COMMAND -h):\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(&usage, "  %-8s %s\n", name, commands[name].summary)
+	}
+	return usage.String()
+}
+
+// newFlagSet prepares the flag set of a subcommand which reports usage and parsing errors to errOut
+func newFlagSet(name string, args string, errOut outFunc) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	var buffered strings.Builder
+	flags.SetOutput(&buffered)
+	flags.Usage = func() {
+		fmt.Fprintf(&buffered, "Usage: ndocid %s [FLAGS] %s\n", name, args)
+		flags.PrintDefaults()
+		errOut("%s", strings.TrimSuffix(buffered.String(), "\n"))
+		buffered.Reset()
+	}
+	return flags
+}
+
+// parseFlags parses the arguments of a subcommand, if parsing fails ok is false and status is the exit code
+func parseFlags(flags *flag.FlagSet, args []string) (status int, ok bool) {
+	switch err := flags.Parse(args); err {
+	case nil:
+		return 0, true
+	case flag.ErrHelp:
+		return 0, false
+	}
+	return 2, false
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+const (
+	csvEncode   = "encode"
+	csvDecode   = "decode"
+	csvValidate = "validate"
+)
+
+type csvOptions struct {
+	mode      string
+	column    string //header name or 1-based number
+	delimiter rune
+	noHeader  bool
+	dates     bool
+	layout    string
+	version   ndocid.Version
+}
+
+// csvRecords splits the input into records without loading it entirely,
+// a record spans several lines if a quoted field contains line breaks
+type csvRecords struct {
+	in        *bufio.Reader
+	delimiter rune
+	line      int //line number the next record starts at
+}
+
+// next returns the fields of the next non-empty record and the line it starts at
+func (r *csvRecords) next() (fields []string, line int, err error) {
+	for {
+		var chunk strings.Builder
+		quotes := 0
+		for {
+			part, err := r.in.ReadString('\n')
+			if chunk.Len() == 0 && r.line == 1 {
+				part = strings.TrimPrefix(part, "\ufeff") //spreadsheets like to start with a byte order mark
+			}
+			chunk.WriteString(part)
+			quotes += strings.Count(part, `"`)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, r.line, err
+			}
+			if quotes%2 == 0 {
+				break
+			}
+		}
+		if chunk.Len() == 0 {
+			return nil, r.line, io.EOF
+		}
+		line = r.line
+		r.line += strings.Count(chunk.String(), "\n")
+		parser := csv.NewReader(strings.NewReader(chunk.String()))
+		parser.Comma = r.delimiter
+		fields, err = parser.Read()
+		if err == io.EOF {
+			continue //blank line
+		}
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				err = parseErr.Err
+			}
+			return nil, line, fmt.Errorf("Bad CSV in line %d: %s", line, err)
+		}
+		return fields, line, nil
+	}
+}
+
+// findColumn resolves the column given by name or number to its index
+func findColumn(column string, header []string) (int, error) {
+	for i, name := range header {
+		if strings.TrimSpace(name) == column {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(column); err == nil && n > 0 {
+		return n - 1, nil
+	}
+	if header == nil {
+		return 0, fmt.Errorf("Column must be given by number without header: %s", column)
+	}
+	return 0, fmt.Errorf("No column %s in header", column)
+}
+
+// csvStatus decodes an ID into the status, value and date columns
+func csvStatus(id string, layout string) (status, value, date string, err error) {
+	v, err, complete := ndocid.Decode(id)
+	switch {
+	case err != nil:
+		return "invalid", "", "", err
+	case !complete:
+		return "partial", "", "", fmt.Errorf("ID incomplete: %s", id)
+	}
+	return "ok", strconv.FormatUint(v, 10), time.Unix(int64(v), 0).Format(layout), nil
+}
+
+// transformCSV appends the new columns to every record, problems of single rows are reported by line number
+func transformCSV(in io.Reader, w io.Writer, opts csvOptions, report func(line int, msg string)) (invalid int, err error) {
+	records := &csvRecords{bufio.NewReader(in), opts.delimiter, 1}
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.delimiter
+	defer writer.Flush()
+
+	var added []string
+	switch opts.mode {
+	case csvEncode:
+		added = []string{"id"}
+	case csvDecode:
+		added = []string{"value", "date", "status"}
+	case csvValidate:
+		added = []string{"status"}
+	default:
+		return 0, fmt.Errorf("Unknown mode: %s", opts.mode)
+	}
+
+	column := -1
+	if opts.noHeader {
+		if column, err = findColumn(opts.column, nil); err != nil {
+			return 0, err
+		}
+	}
+	for {
+		fields, line, err := records.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return invalid, err
+		}
+		if column < 0 {
+			if column, err = findColumn(opts.column, fields); err != nil {
+				return invalid, err
+			}
+			if err := writer.Write(append(fields, added...)); err != nil {
+				return invalid, err
+			}
+			continue
+		}
+
+		var cell string
+		if column < len(fields) {
+			cell = strings.TrimSpace(fields[column])
+		}
+		result := make([]string, len(added))
+		var problem error
+		switch {
+		case column >= len(fields):
+			problem = fmt.Errorf("Row has no column %d", column+1)
+		case cell == "":
+			problem = fmt.Errorf("Empty cell")
+		case opts.mode == csvEncode:
+			var value uint64
+			if value, problem = csvValue(cell, opts); problem == nil {
+				result[0] = opts.version.Encode(value)
+			}
+		case opts.mode == csvDecode:
+			result[2], result[0], result[1], problem = csvStatus(cell, opts.layout)
+		case opts.mode == csvValidate:
+			result[0], _, _, problem = csvStatus(cell, opts.layout)
+		}
+		if problem != nil {
+			if opts.mode != csvEncode && result[len(result)-1] == "" {
+				result[len(result)-1] = "invalid"
+			}
+			invalid++
+			report(line, problem.Error())
+		}
+		if err := writer.Write(append(fields, result...)); err != nil {
+			return invalid, err
+		}
+	}
+	writer.Flush()
+	return invalid, writer.Error()
+}
+
+// csvValue reads the number to encode from the cell, a date in the local time zone if requested
+func csvValue(cell string, opts csvOptions) (uint64, error) {
+	if !opts.dates {
+		value, err := strconv.ParseUint(cell, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Not an unsigned 64 bit integer: %s", cell)
+		}
+		return value, nil
+	}
+	t, err := time.ParseInLocation(opts.layout, cell, time.Local)
+	switch {
+	case err != nil:
+		return 0, fmt.Errorf("Bad date: %s", err)
+	case t.Unix() < 0:
+		return 0, fmt.Errorf("Date before 1970: %s", cell)
+	}
+	return uint64(t.Unix()), nil
+}
+
+// outWriter passes written bytes on to an outFunc
+type outWriter outFunc
+
+func (w outWriter) Write(p []byte) (int, error) {
+	w("%s", p)
+	return len(p), nil
+}
+
+func csvCommand(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("csv", "[FILE]", errOut)
+	var opts csvOptions
+	encode := flags.String("encode", "", "append an ID column generated from the integers (or dates, see -dates) in `column`")
+	decode := flags.String("decode", "", "append value, date and status columns decoded from the IDs in `column`")
+	validate := flags.String("validate", "", "append a status column (ok, invalid or partial) checking the IDs in `column`")
+	delimiter := flags.String("delimiter", ",", "field `delimiter`, \\t or tab for TSV which is the default for files ending in .tsv")
+	flags.BoolVar(&opts.noHeader, "no-header", false, "the first row is data, columns are given by number")
+	flags.BoolVar(&opts.dates, "dates", false, "encode dates formatted according to -layout instead of integers")
+	flags.StringVar(&opts.layout, "layout", "2006-01-02 15:04:05", "Go time `layout` of dates read and written, evaluated in the machine's time zone")
+	version2 := flags.Bool("2", false, "generate version 2 IDs")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	for mode, column := range map[string]string{csvEncode: *encode, csvDecode: *decode, csvValidate: *validate} {
+		if column == "" {
+			continue
+		}
+		if opts.mode != "" {
+			errOut("Only one of -encode, -decode and -validate allowed")
+			return 2
+		}
+		opts.mode, opts.column = mode, column
+	}
+	if opts.mode == "" || flags.NArg() > 1 {
+		errOut("One of -encode, -decode or -validate and at most one file expected (see ndocid csv -h)")
+		return 2
+	}
+	opts.version = ndocid.V1
+	if *version2 {
+		opts.version = ndocid.V2
+	}
+
+	name, in := "(standard input)", io.Reader(os.Stdin)
+	if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			errOut("%s", err)
+			return 2
+		}
+		defer file.Close()
+		name, in = flags.Arg(0), file
+		if strings.EqualFold(filepath.Ext(name), ".tsv") {
+			opts.delimiter = '\t'
+		}
+	}
+	delimiterSet := false
+	flags.Visit(func(f *flag.Flag) { delimiterSet = delimiterSet || f.Name == "delimiter" })
+	if delimiterSet || opts.delimiter == 0 {
+		switch *delimiter {
+		case `\t`, "tab":
+			opts.delimiter = '\t'
+		default:
+			runes := []rune(*delimiter)
+			if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+				errOut("Delimiter must be a single character other than quote and line break: %q", *delimiter)
+				return 2
+			}
+			opts.delimiter = runes[0]
+		}
+	}
+
+	invalid, err := transformCSV(in, outWriter(out), opts, func(line int, msg string) {
+		errOut("%s:%d: %s", name, line, msg)
+	})
+	if err != nil {
+		errOut("%s: %s", name, err)
+		return 2
+	}
+	if invalid > 0 {
+		errOut("%d invalid row(s)", invalid)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+func TestTransformCSV(t *testing.T) {
+	assert := func(in string, opts csvOptions, exp string, expReports ...string) {
+		t.Helper()
+		if opts.delimiter == 0 {
+			opts.delimiter = ','
+		}
+		if opts.layout == "" {
+			opts.layout = "2006-01-02 15:04:05"
+		}
+		if opts.version == 0 {
+			opts.version = 1
+		}
+		var out strings.Builder
+		var reports []string
+		invalid, err := transformCSV(strings.NewReader(in), &out, opts, func(line int, msg string) {
+			reports = append(reports, fmt.Sprintf("%d: %s", line, msg))
+		})
+		if err != nil || out.String() != exp {
+			t.Errorf("expected output\n%s\nbut got\n%s(%v)", exp, out.String(), err)
+		}
+		if invalid != len(expReports) || strings.Join(reports, "\n") != strings.Join(expReports, "\n") {
+			t.Errorf("expected reports %q but got %q", expReports, reports)
+		}
+	}
+
+	assert("name,number\n\"Smith, J.\",1552572000\n\n\"multi\nline\",-1\nempty,\nshort\n",
+		csvOptions{mode: csvEncode, column: "number"},
+		"name,number,id\n\"Smith, J.\",1552572000,72639D77LD\n\"multi\nline\",-1,\nempty,,\nshort,\n",
+		"4: Not an unsigned 64 bit integer: -1", "6: Empty cell", "7: Row has no column 2")
+	//dates are read and written in the local time zone
+	date := time.Unix(1552572000, 0).Format("2006-01-02 15:04:05")
+	assert("\ufeff"+date+"\t1\n", csvOptions{mode: csvEncode, column: "1", delimiter: '\t', noHeader: true, dates: true, version: 2},
+		date+"\t1\t72639Z77LD8\n")
+	_, typo, _ := ndocid.Decode("72639D77DL")
+	assert("id\n72639D77LD\n72639d77ld\n72639Z\n72639D77DL\n",
+		csvOptions{mode: csvDecode, column: "id"},
+		"id,value,date,status\n72639D77LD,1552572000,"+date+",ok\n72639d77ld,1552572000,"+date+",ok\n72639Z,,,partial\n72639D77DL,,,invalid\n",
+		"4: ID incomplete: 72639Z", "5: "+typo.Error())
+	assert("a;id\nx;72639Z77LD8\n", csvOptions{mode: csvValidate, column: "2", delimiter: ';'}, "a;id;status\nx;72639Z77LD8;ok\n")
+
+	for _, bad := range []string{"x\n\"open\n", "x\na\"b\n"} {
+		_, err := transformCSV(strings.NewReader(bad), &strings.Builder{}, csvOptions{mode: csvValidate, column: "x", delimiter: ','}, func(int, string) {})
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%q: expected error in line 2 but got %v", bad, err)
+		}
+	}
+	_, err := transformCSV(strings.NewReader("a,b\n"), &strings.Builder{}, csvOptions{mode: csvValidate, column: "c", delimiter: ','}, func(int, string) {})
+	if err == nil {
+		t.Error("expected missing column to fail")
+	}
+}
+
+func TestCSVCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.tsv")
+	os.WriteFile(path, []byte("id\tnote\n72639D77LD\tok\n72639D77DL\ttypo\n"), 0644)
+	var out, errOut string
+	if status := csvCommand([]string{"-validate", "id", path}, spyIntoString(&out), spyIntoString(&errOut)); status != 1 {
+		t.Errorf("expected status 1 but got %d", status)
+	}
+	if out != "id\tnote\tstatus\n72639D77LD\tok\tok\n72639D77DL\ttypo\tinvalid\n" || !strings.HasPrefix(errOut, path+":3: ") {
+		t.Errorf("unexpected output %q and errors %q", out, errOut)
+	}
+	if status := csvCommand([]string{"-validate", "id", "-decode", "id", path}, silentOut, silentOut); status != 2 {
+		t.Errorf("expected status 2 but got %d", status)
+	}
+}
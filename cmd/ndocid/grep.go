@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n2code/ndocid"
+)
+
+// grepLines reports the IDs found in every line of the input, found is true if there was at least one
+func grepLines(name string, in io.Reader, nearMisses bool, out outFunc) (found bool, err error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		for _, c := range ndocid.FindCandidates(line) {
+			column := utf8.RuneCountInString(line[:c.Start]) + 1
+			if c.Valid() {
+				found = true
+				out("%s:%d:%d: %s %d\n", name, lineNo, column, c.Canonical(), c.Value)
+				continue
+			}
+			if !nearMisses {
+				continue
+			}
+			//only the likely corrections are of interest if there are any, they come first
+			suggestions := ndocid.Suggest(c.Text)
+			var corrections []string
+			for _, correction := range suggestions {
+				if correction.Likely || !suggestions[0].Likely {
+					corrections = append(corrections, correction.ID)
+				}
+			}
+			if len(corrections) > 0 {
+				found = true
+				out("%s:%d:%d: %s near miss of %s\n", name, lineNo, column, c.Text, strings.Join(corrections, " "))
+			}
+		}
+	}
+	return found, scanner.Err()
+}
+
+func grep(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("grep", "[FILE...]", errOut)
+	nearMisses := flags.Bool("near", false, "also report invalid IDs which are a single edit away from valid ones")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+
+	found, failed := false, false
+	search := func(name string, in io.Reader) {
+		f, err := grepLines(name, in, *nearMisses, out)
+		found = found || f
+		if err != nil {
+			errOut("%s: %s", name, err)
+			failed = true
+		}
+	}
+	if flags.NArg() == 0 {
+		search("(standard input)", os.Stdin)
+	}
+	for _, name := range flags.Args() {
+		if name == "-" {
+			search("(standard input)", os.Stdin)
+			continue
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			errOut("%s", err)
+			failed = true
+			continue
+		}
+		search(name, file)
+		file.Close()
+	}
+	switch {
+	case failed:
+		return 2
+	case !found:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrepLines(t *testing.T) {
+	in := "Ref 72639D77LD ✓ and 22222317LD\ntypo 52247CRMYT\nnothing 20190314150000\n"
+	assert := func(nearMisses bool, expFound bool, exp string) {
+		t.Helper()
+		var out string
+		found, err := grepLines("notes.txt", strings.NewReader(in), nearMisses, spyIntoString(&out))
+		if err != nil || found != expFound {
+			t.Errorf("expected found %t but got %t (%v)", expFound, found, err)
+		}
+		if out != exp {
+			t.Errorf("expected output\n%s\nbut got\n%s", exp, out)
+		}
+	}
+	assert(false, true, "notes.txt:1:5: 72639D77LD 1552572000\nnotes.txt:1:22: 222223I7LD 1552609280\n")
+	assert(true, true, "notes.txt:1:5: 72639D77LD 1552572000\nnotes.txt:1:22: 222223I7LD 1552609280\n"+
+		"notes.txt:2:6: 52247CRMYT near miss of 52247CMRYT 52247CRMTY\n")
+
+	found, _ := grepLines("empty.txt", strings.NewReader("52247CRMYT"), false, silentOut)
+	if found {
+		t.Error("expected nothing to be found")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// issuer hands out values which are never reused, the last one issued is persisted in a state file.
+// Processes sharing the state file take turns by locking a file next to it.
+type issuer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// defaultIssuerState is the state file shared by all commands issuing IDs unless told otherwise
+func defaultIssuerState() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "ndocid", "issued")
+}
+
+func newIssuer(path string) *issuer {
+	return &issuer{path: path}
+}
+
+// last returns the last value issued, ok is false if there was none yet
+func (s *issuer) last() (value uint64, ok bool, err error) {
+	content, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	value, err = strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("Corrupt state file %s: %s", s.path, err)
+	}
+	return value, true, nil
+}
+
+const issuerLockTimeout = 10 * time.Second
+
+// lock locks the lock file next to the state file, waiting for other processes holding it, and returns the function
+// releasing it. The lock file stays in place: removing it could let a waiting process lock the removed file while
+// another one creates and locks a new one.
+func (s *issuer) lock() (unlock func(), err error) {
+	path := s.path + ".lock"
+	deadline := time.Now().Add(issuerLockTimeout)
+	for {
+		f, ok, err := tryLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() { f.Close() }, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("State file %s is locked by another process issuing IDs", s.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// issue returns the wanted value or, if that is not above the last one issued, the value following the last one
+func (s *issuer) issue(wanted uint64) (uint64, error) {
+	return s.issueRange(wanted, 1)
+}
+
+// issueRange reserves n consecutive values starting like issue and returns the first of them
+func (s *issuer) issueRange(wanted uint64, n uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return 0, err
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	last, ok, err := s.last()
+	if err != nil {
+		return 0, err
+	}
+	first := wanted
+	if ok && first <= last {
+		if last == ^uint64(0) {
+			return 0, fmt.Errorf("All values issued")
+		}
+		first = last + 1
+	}
+	if n == 0 || first+(n-1) < first {
+		return 0, fmt.Errorf("Cannot issue %d values from %d", n, first)
+	}
+	//replacing the file by renaming never leaves a partially written state behind
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	_, err = temp.WriteString(strconv.FormatUint(first+(n-1), 10) + "\n")
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return 0, err
+	}
+	return first, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestIssuer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "issued")
+	s := newIssuer(path)
+	assert := func(wanted, exp uint64) {
+		t.Helper()
+		value, err := s.issue(wanted)
+		if err != nil || value != exp {
+			t.Errorf("wanted %d: expected %d but got %d (%v)", wanted, exp, value, err)
+		}
+	}
+	assert(100, 100)
+	assert(100, 101)
+	assert(50, 102)
+	assert(200, 200)
+
+	//a restarted issuer continues where the previous one stopped
+	s = newIssuer(path)
+	assert(200, 201)
+
+	if first, err := s.issueRange(1, 10); err != nil || first != 202 {
+		t.Errorf("expected range from 202 but got %d (%v)", first, err)
+	}
+	assert(1, 212)
+	if _, err := s.issueRange(1, ^uint64(0)); err == nil {
+		t.Error("expected range exceeding 64 bits to be rejected")
+	}
+
+	os.WriteFile(path, []byte("garbage"), 0644)
+	if _, err := s.issue(1); err == nil {
+		t.Error("expected corrupt state to be reported")
+	}
+	os.WriteFile(path, []byte("18446744073709551615\n"), 0644)
+	if _, err := s.issue(1); err == nil {
+		t.Error("expected exhaustion to be reported")
+	}
+}
+
+func TestIssuersSharingState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "issued")
+	//separate issuers like separate processes only share the state file
+	issuers := []*issuer{newIssuer(path), newIssuer(path)}
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for _, s := range issuers {
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(s *issuer) {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					value, err := s.issue(1)
+					mu.Lock()
+					if err != nil || seen[value] {
+						t.Errorf("issued %d twice (%v)", value, err)
+					}
+					seen[value] = true
+					mu.Unlock()
+				}
+			}(s)
+		}
+	}
+	wg.Wait()
+	if len(seen) != 200 {
+		t.Errorf("expected 200 values but got %d", len(seen))
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected only the state and lock files to remain but found %d files", len(entries))
+	}
+
+	//the lock is held until released, a lock file left behind by a crashed process is not locked
+	unlock, err := issuers[0].lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, ok, err := tryLock(path + ".lock"); ok || err != nil {
+		t.Errorf("expected lock to be held but got %v (%v)", ok, err)
+		f.Close()
+	}
+	unlock()
+	if value, err := issuers[1].issue(1); err != nil || value != 201 {
+		t.Errorf("expected 201 after unlocking but got %d (%v)", value, err)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n2code/ndocid"
+	"github.com/n2code/ndocid/barcode"
+)
+
+// labelLayout describes a label sheet, all lengths in millimeters
+type labelLayout struct {
+	pageWidth, pageHeight                            float64
+	cols, rows                                       int
+	marginTop, marginRight, marginBottom, marginLeft float64
+	gapX, gapY                                       float64
+}
+
+var pageSizes = map[string][2]float64{
+	"a4":     {210, 297},
+	"letter": {215.9, 279.4},
+}
+
+// labelSize returns the size of a single label which follows from the page, margins and gaps
+func (l labelLayout) labelSize() (width, height float64) {
+	width = (l.pageWidth - l.marginLeft - l.marginRight - float64(l.cols-1)*l.gapX) / float64(l.cols)
+	height = (l.pageHeight - l.marginTop - l.marginBottom - float64(l.rows-1)*l.gapY) / float64(l.rows)
+	return
+}
+
+// origin returns the lower left corner of label i on its sheet, counted row by row from the top left
+func (l labelLayout) origin(i int) (x, y float64) {
+	width, height := l.labelSize()
+	col, row := i%l.cols, i/l.cols%l.rows
+	x = l.marginLeft + float64(col)*(width+l.gapX)
+	y = l.pageHeight - l.marginTop - float64(row)*(height+l.gapY) - height
+	return
+}
+
+// parseLengths reads comma-separated millimeters, expanded like CSS margins to top, right, bottom, left
+func parseLengths(s string) (lengths [4]float64, err error) {
+	parts := strings.Split(s, ",")
+	var values []float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || v < 0 {
+			return lengths, fmt.Errorf("Bad length in %q, expected millimeters", s)
+		}
+		values = append(values, v)
+	}
+	switch len(values) {
+	case 1:
+		return [4]float64{values[0], values[0], values[0], values[0]}, nil
+	case 2:
+		return [4]float64{values[0], values[1], values[0], values[1]}, nil
+	case 4:
+		return [4]float64{values[0], values[1], values[2], values[3]}, nil
+	}
+	return lengths, fmt.Errorf("Expected 1, 2 or 4 lengths: %q", s)
+}
+
+const ptPerMM = 72 / 25.4
+
+// pdfDocument collects numbered objects and writes them with cross-reference table
+type pdfDocument struct {
+	objects []string
+}
+
+// add appends an object and returns its number
+func (d *pdfDocument) add(object string) int {
+	d.objects = append(d.objects, object)
+	return len(d.objects)
+}
+
+func (d *pdfDocument) bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, object := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfStream(content string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+}
+
+func pdfString(s string) string {
+	return "(" + strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s) + ")"
+}
+
+// courierAdvance is the width of every character of the Courier fonts relative to the font size
+const courierAdvance = 0.6
+
+// labelContent draws a label into the page content: the barcode above the grouped ID and the optional caption,
+// x, y, width and height in points
+func labelContent(page *strings.Builder, symbol *barcode.Symbol, id string, caption string, x, y, width, height float64) {
+	const padding = 2 * ptPerMM
+	x, y, width, height = x+padding, y+padding, width-2*padding, height-2*padding
+	text := ndocid.Group(id, " ")
+	size := width / (courierAdvance * float64(len(text)))
+	if size > 14 {
+		size = 14
+	}
+	captionSize := 0.7 * size
+	textHeight := size
+	if caption != "" {
+		textHeight += captionSize
+	}
+	textX := x + (width-courierAdvance*size*float64(len(text)))/2
+	baseline := y + textHeight - 0.8*size
+	fmt.Fprintf(page, "BT /F1 %.2f Tf %.2f %.2f Td %s Tj ET\n", size, textX, baseline, pdfString(text))
+	if caption != "" {
+		captionX := x + (width-courierAdvance*captionSize*float64(len(caption)))/2
+		fmt.Fprintf(page, "BT /F2 %.2f Tf %.2f %.2f Td %s Tj ET\n", captionSize, captionX, y, pdfString(caption))
+	}
+
+	//the barcode takes the remaining space including its quiet zone
+	areaY, areaHeight := y+textHeight+0.2*size, height-textHeight-0.2*size
+	columns := symbol.Width() + 2*symbol.Quiet
+	module := width / float64(columns)
+	barHeight := areaHeight
+	if !symbol.Linear() {
+		if side := areaHeight / float64(columns); side < module {
+			module = side
+		}
+		barHeight = module
+	}
+	left := x + (width-module*float64(columns))/2 + module*float64(symbol.Quiet)
+	top := areaY + areaHeight
+	if !symbol.Linear() {
+		top = areaY + (areaHeight+module*float64(symbol.Width()))/2
+	}
+	for row, modules := range symbol.Modules {
+		for col := 0; col < len(modules); {
+			if !modules[col] {
+				col++
+				continue
+			}
+			run := 0
+			for col+run < len(modules) && modules[col+run] {
+				run++
+			}
+			fmt.Fprintf(page, "%.3f %.3f %.3f %.3f re\n", left+module*float64(col), top-barHeight*float64(row+1), module*float64(run), barHeight)
+			col += run
+		}
+	}
+	page.WriteString("f\n")
+}
+
+// labelSheets renders the IDs onto as many sheets as needed, the first skip positions of the first sheet stay empty
+func labelSheets(layout labelLayout, ids []string, skip int, withDate bool, encode func(string) (*barcode.Symbol, error)) ([]byte, error) {
+	var doc pdfDocument
+	doc.add("<< /Type /Catalog /Pages 2 0 R >>")
+	doc.add("") //page tree, known when all pages are added
+	doc.add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold >>")
+	doc.add("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	width, height := layout.labelSize()
+	perSheet := layout.cols * layout.rows
+
+	var pages []string
+	var page strings.Builder
+	flush := func() {
+		contents := doc.add(pdfStream(page.String()))
+		pageObject := doc.add(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			layout.pageWidth*ptPerMM, layout.pageHeight*ptPerMM, contents))
+		pages = append(pages, fmt.Sprintf("%d 0 R", pageObject))
+		page.Reset()
+	}
+	for i, id := range ids {
+		position := skip + i
+		if position > skip && position%perSheet == 0 {
+			flush()
+		}
+		symbol, err := encode(id)
+		if err != nil {
+			return nil, err
+		}
+		var caption string
+		if withDate {
+			value, _, _ := ndocid.Decode(id)
+			caption = time.Unix(int64(value), 0).Format("2006-01-02")
+		}
+		x, y := layout.origin(position)
+		labelContent(&page, symbol, id, caption, x*ptPerMM, y*ptPerMM, width*ptPerMM, height*ptPerMM)
+	}
+	flush()
+	doc.objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pages, " "), len(pages))
+	return doc.bytes(), nil
+}
+
+func labels(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("labels", "", errOut)
+	output := flags.String("o", "labels.pdf", "PDF `file` to write")
+	count := flags.Int("count", 0, "`number` of labels, by default the rest of the first sheet")
+	skip := flags.Int("skip", 0, "`number` of labels already used on the first sheet")
+	pageName := flags.String("page", "a4", "page `size`: a4 or letter")
+	cols := flags.Int("cols", 3, "`number` of labels side by side")
+	rows := flags.Int("rows", 8, "`number` of labels one below the other")
+	margins := flags.String("margins", "13.1,7.2", "page `margins` in millimeters like in CSS: all, vertical,horizontal or top,right,bottom,left")
+	gap := flags.String("gap", "0,2.5", "`gap` between labels in millimeters like in CSS: both or vertical,horizontal")
+	withDate := flags.Bool("date", false, "print the date of the ID below it")
+	symbology := flags.String("type", "code128", "barcode `symbology`: code128, code39 or qr")
+	state := flags.String("state", defaultIssuerState(), "`file` remembering the last ID issued, shared with other commands issuing IDs")
+	version2 := flags.Bool("2", false, "generate version 2 IDs")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 0 {
+		errOut("No arguments expected (see ndocid labels -h)")
+		return 2
+	}
+
+	size, ok := pageSizes[*pageName]
+	encode, known := symbologies[*symbology]
+	m, marginsErr := parseLengths(*margins)
+	g, gapErr := parseLengths(*gap)
+	switch {
+	case !ok:
+		errOut("Unknown page size: %s", *pageName)
+		return 2
+	case !known:
+		errOut("Unknown symbology: %s", *symbology)
+		return 2
+	case marginsErr != nil:
+		errOut("%s", marginsErr)
+		return 2
+	case gapErr != nil:
+		errOut("%s", gapErr)
+		return 2
+	case strings.Count(*gap, ",") > 1:
+		errOut("Expected 1 or 2 gaps: %q", *gap)
+		return 2
+	}
+	layout := labelLayout{size[0], size[1], *cols, *rows, m[0], m[1], m[2], m[3], g[1], g[0]}
+	perSheet := *cols * *rows
+	if width, height := layout.labelSize(); *cols < 1 || *rows < 1 || width < 10 || height < 10 {
+		errOut("Labels too small, at least 10 by 10 millimeters needed")
+		return 2
+	}
+	if *skip < 0 || *skip >= perSheet || *count < 0 {
+		errOut("Skip must be less than the %d labels per sheet and count must not be negative", perSheet)
+		return 2
+	}
+	if *count == 0 {
+		*count = perSheet - *skip
+	}
+
+	first, err := newIssuer(*state).issueRange(uint64(time.Now().Unix()), uint64(*count))
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	version := ndocid.V1
+	if *version2 {
+		version = ndocid.V2
+	}
+	ids := make([]string, *count)
+	for i := range ids {
+		ids[i] = version.Encode(first + uint64(i))
+	}
+	pdf, err := labelSheets(layout, ids, *skip, *withDate, encode)
+	if err == nil {
+		err = os.WriteFile(*output, pdf, 0644)
+	}
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	for _, id := range ids {
+		out("%s\n", id)
+	}
+	return 0
+}
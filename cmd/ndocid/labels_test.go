@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/n2code/ndocid"
+	"github.com/n2code/ndocid/barcode"
+)
+
+func TestLabelLayout(t *testing.T) {
+	margins, _ := parseLengths("13.1,7.2")
+	gap, _ := parseLengths("0,2.5")
+	l := labelLayout{210, 297, 3, 8, margins[0], margins[1], margins[2], margins[3], gap[1], gap[0]}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.01 }
+	if width, height := l.labelSize(); !near(width, 63.53) || !near(height, 33.85) {
+		t.Errorf("unexpected label size %.2f x %.2f", width, height)
+	}
+	if x, y := l.origin(0); !near(x, 7.2) || !near(y, 297-13.1-33.85) {
+		t.Errorf("unexpected origin %.2f, %.2f of first label", x, y)
+	}
+	if x, y := l.origin(5 + 24); !near(x, 7.2+2*(63.53+2.5)) || !near(y, 297-13.1-2*33.85) {
+		t.Errorf("unexpected origin %.2f, %.2f of sixth label on second sheet", x, y)
+	}
+	for _, bad := range []string{"", "1,2,3", "-1", "x"} {
+		if _, err := parseLengths(bad); err == nil {
+			t.Errorf("%q: expected failure", bad)
+		}
+	}
+}
+
+// checkPDF verifies that the cross-reference table points at the objects
+func checkPDF(t *testing.T, pdf string) {
+	t.Helper()
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindStringSubmatch(pdf)
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || match == nil {
+		t.Fatal("PDF header or trailer missing")
+	}
+	xref, _ := strconv.Atoi(match[1])
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatal("startxref does not point at cross-reference table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if !strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", i+1)) {
+			t.Errorf("cross-reference of object %d does not point at it", i+1)
+		}
+	}
+	for _, stream := range regexp.MustCompile(`<< /Length (\d+) >>\nstream\n`).FindAllStringSubmatchIndex(pdf, -1) {
+		length, _ := strconv.Atoi(pdf[stream[2]:stream[3]])
+		if !strings.HasPrefix(pdf[stream[1]+length:], "\nendstream") {
+			t.Error("stream length does not match")
+		}
+	}
+}
+
+func TestLabelSheets(t *testing.T) {
+	l := labelLayout{210, 297, 2, 2, 10, 10, 10, 10, 5, 5}
+	ids := []string{"72639D77LD", "72639F77LD", "72639H77LD", "72639I77LD"}
+	pdf, err := labelSheets(l, ids, 1, true, barcode.Code128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPDF(t, string(pdf))
+	if pages := strings.Count(string(pdf), "/Type /Page "); pages != 2 {
+		t.Errorf("expected 2 pages but got %d", pages)
+	}
+	if !strings.Contains(string(pdf), "(72639 D77LD) Tj") || !strings.Contains(string(pdf), "(2019-03-14) Tj") {
+		t.Error("expected grouped ID and date caption")
+	}
+
+	qr, err := labelSheets(l, ids[:1], 0, false, barcode.QR)
+	if err != nil || strings.Contains(string(qr), "2019") {
+		t.Errorf("expected QR code without caption (%v)", err)
+	}
+	checkPDF(t, string(qr))
+}
+
+func TestLabelsCommand(t *testing.T) {
+	dir := t.TempDir()
+	state, output := filepath.Join(dir, "issued"), filepath.Join(dir, "labels.pdf")
+	var issued string
+	if status := labels([]string{"-state", state, "-o", output, "-skip", "20", "-count", "6"}, spyIntoString(&issued), silentOut); status != 0 {
+		t.Fatalf("expected status 0 but got %d", status)
+	}
+	ids := strings.Fields(issued)
+	if len(ids) != 6 {
+		t.Fatalf("expected 6 IDs but got %q", issued)
+	}
+	pdf, _ := os.ReadFile(output)
+	checkPDF(t, string(pdf))
+	if pages := strings.Count(string(pdf), "/Type /Page "); pages != 2 {
+		t.Errorf("expected 2 pages but got %d", pages)
+	}
+
+	//the next sheet continues after the last ID issued
+	var next string
+	labels([]string{"-state", state, "-o", output, "-count", "1", "-2"}, spyIntoString(&next), silentOut)
+	last, _, _ := ndocid.Decode(ids[5])
+	if value, _, _ := ndocid.Decode(strings.TrimSpace(next)); value != last+1 {
+		t.Errorf("expected %d but got %d", last+1, value)
+	}
+
+	for _, bad := range [][]string{{"-page", "a5"}, {"-skip", "24"}, {"-cols", "30"}, {"-margins", "1,2,3"}, {"-type", "ean"}} {
+		if status := labels(append(bad, "-state", state, "-o", output), silentOut, silentOut); status != 2 {
+			t.Errorf("%v: expected status 2 but got %d", bad, status)
+		}
+	}
+}
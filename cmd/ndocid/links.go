@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n2code/ndocid"
+)
+
+// note is a file of the collection, ID is empty if it defines none
+type note struct {
+	Path  string `json:"path"`
+	ID    string `json:"id,omitempty"`
+	value uint64
+}
+
+// noteLink is a reference like [[96822L9IPD]] or [[96822L9IPD|label]], Line and Column are 1-based
+type noteLink struct {
+	From   string `json:"from"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Target string `json:"target"`
+}
+
+type linkProblem struct {
+	noteLink
+	Kind        string   `json:"kind"` //invalid or dangling link, or invalid-id defined by the note From
+	Message     string   `json:"message"`
+	Corrections []string `json:"corrections,omitempty"`
+}
+
+type linkReport struct {
+	Notes      int                 `json:"notes"`
+	Links      int                 `json:"links"`
+	Problems   []linkProblem       `json:"problems"`
+	Duplicates map[string][]string `json:"duplicates"` //paths of notes defining the same ID
+	Orphans    []string            `json:"orphans"`    //paths of notes no other note links to
+	Backlinks  map[string][]string `json:"backlinks,omitempty"`
+}
+
+var wikiLink = regexp.MustCompile(`\[\[([^\]|#]+)(?:[|#][^\]]*)?\]\]`)
+
+// frontMatterID returns the id of a YAML front matter block at the start of the lines and where it is, 1-based
+func frontMatterID(lines []string) (id string, line int, column int) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "", 0, 0
+	}
+	for i, l := range lines[1:] {
+		if t := strings.TrimSpace(l); t == "---" || t == "..." {
+			break
+		}
+		if key := strings.SplitN(l, ":", 2); len(key) == 2 && strings.TrimSpace(key[0]) == "id" {
+			id = strings.Trim(strings.TrimSpace(key[1]), `"'`)
+			start := len(key[0]) + 1 + strings.Index(key[1], id)
+			return id, i + 2, utf8.RuneCountInString(l[:start]) + 1
+		}
+	}
+	return "", 0, 0
+}
+
+// noteID takes the ID from the front matter or else from the first ID in the file name. Where it is defined is
+// returned as a link to it, in line 0 for the file name. The error tells why a defined ID is invalid or incomplete.
+func noteID(path string, lines []string) (id string, value uint64, where noteLink, err error) {
+	id, line, column := frontMatterID(lines)
+	if id == "" {
+		candidates := ndocid.FindCandidates(filepath.Base(path))
+		if len(candidates) == 0 {
+			return "", 0, where, nil
+		}
+		id, column = candidates[0].Text, utf8.RuneCountInString(filepath.Base(path)[:candidates[0].Start])+1
+	}
+	where = noteLink{path, line, column, id}
+	value, err, complete := ndocid.Decode(id)
+	if err == nil && !complete {
+		err = fmt.Errorf("ID incomplete, needs further characters")
+	}
+	return id, value, where, err
+}
+
+// linksOf finds all references to IDs, i.e. links whose whole target looks like an ID.
+// Links to titles or dates like [[2024-01-05]] are ignored.
+func linksOf(path string, lines []string) (links []noteLink) {
+	for i, line := range lines {
+		for _, m := range wikiLink.FindAllStringSubmatchIndex(line, -1) {
+			target := strings.TrimSpace(line[m[2]:m[3]])
+			if c := ndocid.FindCandidates(target); len(c) != 1 || c[0].Start != 0 || c[0].End != len(target) {
+				continue
+			}
+			links = append(links, noteLink{path, i + 1, utf8.RuneCountInString(line[:m[0]]) + 1, target})
+		}
+	}
+	return
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// checkLinks indexes the notes below the roots and checks all links between them
+func checkLinks(roots []string, extensions []string, withBacklinks bool) (report linkReport, err error) {
+	var notes []note
+	var links []noteLink
+	var invalidIDs []linkProblem
+	visit := func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		matching := false
+		for _, ext := range extensions {
+			matching = matching || strings.EqualFold(filepath.Ext(path), ext)
+		}
+		if !matching {
+			return nil
+		}
+		lines, err := readLines(path)
+		if err != nil {
+			return err
+		}
+		n := note{Path: path}
+		if id, value, where, err := noteID(path, lines); err != nil {
+			invalidIDs = append(invalidIDs, linkProblem{where, "invalid-id", err.Error(), likelyCorrections(id)})
+		} else {
+			n.ID, n.value = id, value
+		}
+		notes = append(notes, n)
+		links = append(links, linksOf(path, lines)...)
+		return nil
+	}
+	for _, root := range roots {
+		if err = filepath.WalkDir(root, visit); err != nil {
+			return
+		}
+	}
+
+	byValue := make(map[uint64][]note)
+	for _, n := range notes {
+		if n.ID != "" {
+			byValue[n.value] = append(byValue[n.value], n)
+		}
+	}
+	report = linkReport{Notes: len(notes), Links: len(links), Problems: invalidIDs, Duplicates: map[string][]string{}, Orphans: []string{}}
+	if report.Problems == nil {
+		report.Problems = []linkProblem{}
+	}
+	if withBacklinks {
+		report.Backlinks = make(map[string][]string)
+	}
+	linked := make(map[uint64]bool)
+	for _, l := range links {
+		value, err, complete := ndocid.Decode(l.Target)
+		switch {
+		case err != nil || !complete:
+			message := "ID incomplete, needs further characters"
+			if err != nil {
+				message = err.Error()
+			}
+			report.Problems = append(report.Problems, linkProblem{l, "invalid", message, corrections(l.Target, byValue)})
+		case len(byValue[value]) == 0:
+			report.Problems = append(report.Problems, linkProblem{l, "dangling", "No note with this ID", nil})
+		default:
+			target := byValue[value][0]
+			if target.Path != l.From {
+				linked[value] = true
+			}
+			if withBacklinks {
+				report.Backlinks[target.ID] = appendUnique(report.Backlinks[target.ID], l.From)
+			}
+		}
+	}
+	for value, defining := range byValue {
+		if len(defining) > 1 {
+			for _, n := range defining {
+				report.Duplicates[defining[0].ID] = append(report.Duplicates[defining[0].ID], n.Path)
+			}
+		}
+		if !linked[value] {
+			for _, n := range defining {
+				report.Orphans = append(report.Orphans, n.Path)
+			}
+		}
+	}
+	sort.Strings(report.Orphans)
+	return
+}
+
+// corrections lists the valid IDs a single edit away, those of existing notes or else the likely ones
+func corrections(target string, byValue map[uint64][]note) (existing []string) {
+	var likely []string
+	for _, c := range ndocid.Suggest(target) {
+		value, _, _ := ndocid.Decode(c.ID)
+		if len(byValue[value]) > 0 {
+			existing = append(existing, c.ID)
+		} else if c.Likely {
+			likely = append(likely, c.ID)
+		}
+	}
+	if len(existing) == 0 {
+		return likely
+	}
+	return
+}
+
+// likelyCorrections lists the likely valid IDs a single edit away, for an ID defined by a note
+func likelyCorrections(id string) (likely []string) {
+	for _, c := range ndocid.Suggest(id) {
+		if c.Likely {
+			likely = append(likely, c.ID)
+		}
+	}
+	return
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func links(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("links", "PATH...", errOut)
+	extensions := flags.String("ext", ".md,.markdown,.txt", "comma-separated list of note file extensions")
+	withBacklinks := flags.Bool("backlinks", false, "also print which notes link to each note")
+	asJSON := flags.Bool("json", false, "print JSON instead of text")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() == 0 {
+		errOut("No path given (see ndocid links -h)")
+		return 2
+	}
+	report, err := checkLinks(flags.Args(), strings.Split(*extensions, ","), *withBacklinks)
+	if err != nil {
+		errOut("%s", err)
+		return 2
+	}
+
+	if *asJSON {
+		encoded, _ := json.MarshalIndent(report, "", "  ")
+		out("%s\n", encoded)
+	} else {
+		for _, p := range report.Problems {
+			switch {
+			case p.Kind == "invalid-id" && p.Line == 0:
+				out("%s: invalid ID %s in file name: %s", p.From, p.Target, p.Message)
+			case p.Kind == "invalid-id":
+				out("%s:%d:%d: invalid ID %s: %s", p.From, p.Line, p.Column, p.Target, p.Message)
+			default:
+				out("%s:%d:%d: %s link [[%s]]: %s", p.From, p.Line, p.Column, p.Kind, p.Target, p.Message)
+			}
+			if len(p.Corrections) > 0 {
+				out(", did you mean %s?", strings.Join(p.Corrections, " or "))
+			}
+			out("\n")
+		}
+		for _, id := range sortedKeys(report.Duplicates) {
+			out("duplicate ID %s: %s\n", id, strings.Join(report.Duplicates[id], ", "))
+		}
+		for _, path := range report.Orphans {
+			out("orphan: %s\n", path)
+		}
+		for _, id := range sortedKeys(report.Backlinks) {
+			out("backlinks of %s: %s\n", id, strings.Join(report.Backlinks[id], ", "))
+		}
+		out("%d note(s) with %d link(s), %d broken, %d orphan(s)\n", report.Notes, report.Links, len(report.Problems), len(report.Orphans))
+	}
+	if len(report.Problems) > 0 || len(report.Duplicates) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string][]string) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	root := t.TempDir()
+	notes := map[string]string{
+		"contract.md":           "---\nid: 72639D77LD\n---\nSee [[52247CRMTY]], [[52247CRMYT|typo]], [[68495LTTOD]] and [[Some title]]\n",
+		"52247CRMTY minutes.md": "Back to [[72639D77LD#details]]\n",
+		"96822L9IPD.md":         "Lonely note linking to itself [[96822L9IPD]], [[2024-01-05]] and [[3 things]]\n",
+		"copy/72639D77LD.txt":   "Duplicate",
+		"image.png":             "[[12345ABCDE]]",
+	}
+	for name, content := range notes {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	report, err := checkLinks([]string{root}, []string{".md", ".txt"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Notes != 4 || report.Links != 5 {
+		t.Errorf("expected 4 notes with 5 links but got %d and %d", report.Notes, report.Links)
+	}
+	if len(report.Problems) != 2 {
+		t.Fatalf("expected 2 problems but got %+v", report.Problems)
+	}
+	invalid, dangling := report.Problems[0], report.Problems[1]
+	if invalid.Kind != "invalid" || invalid.Line != 4 || invalid.Column != 21 || strings.Join(invalid.Corrections, " ") != "52247CRMTY" {
+		t.Errorf("expected invalid link with correction to existing note but got %+v", invalid)
+	}
+	if dangling.Kind != "dangling" || dangling.Target != "68495LTTOD" {
+		t.Errorf("expected dangling link but got %+v", dangling)
+	}
+	if len(report.Duplicates["72639D77LD"]) != 2 {
+		t.Errorf("expected duplicate definition but got %v", report.Duplicates)
+	}
+	if len(report.Orphans) != 1 || filepath.Base(report.Orphans[0]) != "96822L9IPD.md" {
+		t.Errorf("expected single orphan but got %v", report.Orphans)
+	}
+	if backlinks := report.Backlinks["52247CRMTY"]; len(backlinks) != 1 || filepath.Base(backlinks[0]) != "contract.md" {
+		t.Errorf("unexpected backlinks %v", report.Backlinks)
+	}
+}
+
+func TestInvalidNoteIDs(t *testing.T) {
+	root := t.TempDir()
+	notes := map[string]string{
+		"contract.md":         "---\ntitle: Contract\nid: 72639D77DL\n---\nSee [[52247CRMTY]]\n",
+		"52247CRMYT notes.md": "See [[72639D77LD]]\n",
+		"72639 draft.md":      "No ID",
+	}
+	for name, content := range notes {
+		os.WriteFile(filepath.Join(root, name), []byte(content), 0644)
+	}
+	report, err := checkLinks([]string{root}, []string{".md"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var invalidIDs []linkProblem
+	for _, p := range report.Problems {
+		if p.Kind == "invalid-id" {
+			invalidIDs = append(invalidIDs, p)
+		}
+	}
+	if len(invalidIDs) != 2 {
+		t.Fatalf("expected 2 invalid IDs but got %+v", report.Problems)
+	}
+	byName, contract := invalidIDs[0], invalidIDs[1]
+	if filepath.Base(byName.From) != "52247CRMYT notes.md" || byName.Line != 0 || byName.Column != 1 || !strings.Contains(strings.Join(byName.Corrections, " "), "52247CRMTY") {
+		t.Errorf("expected invalid ID in file name with correction but got %+v", byName)
+	}
+	if filepath.Base(contract.From) != "contract.md" || contract.Line != 3 || contract.Column != 5 || strings.Join(contract.Corrections, " ") != "72639D77LD" {
+		t.Errorf("expected invalid ID in front matter with correction but got %+v", contract)
+	}
+
+	var out string
+	if status := links([]string{root}, spyIntoString(&out), silentOut); status != 1 || !strings.Contains(out, "contract.md:3:5: invalid ID 72639D77DL") {
+		t.Errorf("expected invalid ID to be reported but got %q (status %d)", out, status)
+	}
+}
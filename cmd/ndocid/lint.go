@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+// lintFinding is a problem with the ID in the name of a file
+type lintFinding struct {
+	Path    string `json:"path"`
+	ID      string `json:"id,omitempty"`
+	Kind    string `json:"kind"` //no-id, invalid, partial, duplicate or date-mismatch
+	Message string `json:"message"`
+}
+
+type lintOptions struct {
+	pattern   *regexp.Regexp //the first submatch or, lacking groups, the whole match is the ID
+	tolerance time.Duration  //maximum difference of decoded date and modification time, 0 disables the check
+	requireID bool           //whether files without an ID are reported
+}
+
+// idFromName extracts the ID using the pattern, ok is false if the pattern does not match
+func (o lintOptions) idFromName(name string) (id string, ok bool) {
+	match := o.pattern.FindStringSubmatch(name)
+	switch {
+	case match == nil:
+		return "", false
+	case len(match) > 1:
+		return match[1], true
+	}
+	return match[0], true
+}
+
+// lintTrees walks the trees and returns the findings ordered by path, checked is the number of files carrying IDs.
+// A root may also be a single file.
+func lintTrees(roots []string, opts lintOptions) (findings []lintFinding, checked int, err error) {
+	seen := make(map[uint64][]string) //paths by decoded value
+	visit := func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		id, ok := opts.idFromName(entry.Name())
+		if !ok {
+			if opts.requireID {
+				findings = append(findings, lintFinding{path, "", "no-id", "No ID in file name"})
+			}
+			return nil
+		}
+		checked++
+		value, decodeErr, complete := ndocid.Decode(id)
+		switch {
+		case decodeErr != nil:
+			message := decodeErr.Error()
+			if suggestions := ndocid.Suggest(id); len(suggestions) > 0 && suggestions[0].Likely {
+				message += fmt.Sprintf(", did you mean %s?", suggestions[0].ID)
+			}
+			findings = append(findings, lintFinding{path, id, "invalid", message})
+			return nil
+		case !complete:
+			findings = append(findings, lintFinding{path, id, "partial", "ID incomplete, needs further characters"})
+			return nil
+		}
+		seen[value] = append(seen[value], path)
+		if opts.tolerance > 0 {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			decoded := time.Unix(int64(value), 0)
+			if diff := info.ModTime().Sub(decoded); diff > opts.tolerance || -diff > opts.tolerance {
+				findings = append(findings, lintFinding{path, id, "date-mismatch", fmt.Sprintf(
+					"ID dates from %s but file was modified %s", decoded.Format(time.RFC3339), info.ModTime().Format(time.RFC3339))})
+			}
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if err = filepath.WalkDir(root, visit); err != nil {
+			return
+		}
+	}
+	for _, paths := range seen {
+		if len(paths) < 2 {
+			continue
+		}
+		for _, path := range paths {
+			id, _ := opts.idFromName(filepath.Base(path))
+			findings = append(findings, lintFinding{path, id, "duplicate", fmt.Sprintf(
+				"Same ID as %s", strings.Join(without(paths, path), ", "))})
+		}
+	}
+	sort.SliceStable(findings, func(a, b int) bool { return findings[a].Path < findings[b].Path })
+	return
+}
+
+func without(paths []string, path string) (others []string) {
+	for _, p := range paths {
+		if p != path {
+			others = append(others, p)
+		}
+	}
+	return
+}
+
+func lint(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("lint", "PATH...", errOut)
+	pattern := flags.String("pattern", `^([0-9]{5}[0-9A-Z]+)([^0-9A-Za-z]|$)`, "regular `expression` finding the ID in file names, its first group or else the whole match is the ID")
+	tolerance := flags.Duration("tolerance", 0, "report IDs whose date differs from the modification time by more than this `duration`, e.g. 24h")
+	requireID := flags.Bool("require", false, "report files without ID in their name")
+	asJSON := flags.Bool("json", false, "print JSON instead of text")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() == 0 {
+		errOut("No path given (see ndocid lint -h)")
+		return 2
+	}
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		errOut("Bad pattern: %s", err)
+		return 2
+	}
+	opts := lintOptions{re, *tolerance, *requireID}
+
+	findings, checked, err := lintTrees(flags.Args(), opts)
+	if err != nil {
+		errOut("%s", err)
+		return 2
+	}
+	if findings == nil {
+		findings = []lintFinding{}
+	}
+
+	if *asJSON {
+		report, _ := json.MarshalIndent(struct {
+			Checked  int           `json:"checked"`
+			Findings []lintFinding `json:"findings"`
+		}{checked, findings}, "", "  ")
+		out("%s\n", report)
+	} else {
+		for _, f := range findings {
+			out("%s: %s: %s\n", f.Path, f.Kind, f.Message)
+		}
+		out("%d file(s) with IDs checked, %d problem(s) found\n", checked, len(findings))
+	}
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
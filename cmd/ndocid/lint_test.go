@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLintTrees(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]time.Time{
+		"72639D77LD_contract.pdf": time.Unix(1552572000, 0),
+		"sub/72639D77LD.pdf":      time.Unix(1552572000, 0),
+		"sub/52247CRMYT_typo.pdf": time.Unix(0, 0),
+		"72639Z_partial.pdf":      time.Unix(0, 0),
+		"68495LTTOD old scan.pdf": time.Unix(1552572000, 0),
+		"notes.txt":               time.Unix(0, 0),
+		"sub/52247CRMTYextra.pdf": time.Unix(0, 0),
+	}
+	for name, mtime := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mtime, mtime)
+	}
+	opts := lintOptions{regexp.MustCompile(`^([0-9]{5}[0-9A-Z]+)([^0-9A-Za-z]|$)`), 24 * time.Hour, true}
+	findings, checked, err := lintTrees([]string{root}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 5 {
+		t.Errorf("expected 5 files with IDs but got %d", checked)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, strings.TrimPrefix(f.Path, root+string(filepath.Separator))+" "+f.Kind)
+	}
+	expected := []string{
+		"68495LTTOD old scan.pdf date-mismatch",
+		"72639D77LD_contract.pdf duplicate",
+		"72639Z_partial.pdf partial",
+		"notes.txt no-id",
+		"sub/52247CRMTYextra.pdf no-id",
+		"sub/52247CRMYT_typo.pdf invalid",
+		"sub/72639D77LD.pdf duplicate",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected findings\n%s\nbut got\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if !strings.Contains(findings[5].Message, ", did you mean 52247C") {
+		t.Errorf("expected suggestion in %q", findings[5].Message)
+	}
+}
+
+func TestLintStatus(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "72639D77LD_a.pdf"), nil, 0644)
+	var out string
+	if status := lint([]string{root}, spyIntoString(&out), silentOut); status != 0 {
+		t.Errorf("expected status 0 but got %d: %s", status, out)
+	}
+	os.WriteFile(filepath.Join(root, "72639D77DL_b.pdf"), nil, 0644)
+	out = ""
+	if status := lint([]string{"-json", root}, spyIntoString(&out), silentOut); status != 1 || !strings.Contains(out, `"kind": "invalid"`) {
+		t.Errorf("expected status 1 and JSON report but got %d: %s", status, out)
+	}
+	if status := lint([]string{filepath.Join(root, "missing")}, silentOut, silentOut); status != 2 {
+		t.Errorf("expected status 2 for missing path but got %d", status)
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+// The language server speaks JSON-RPC 2.0 over stdio, framed by Content-Length headers.
+// Documents are always synchronized in full and positions are counted in UTF-16 code units as the protocol demands.
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"` //absent for notifications
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+}
+
+type rpcErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type rpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+const rpcMethodNotFound = -32601
+
+// maxRPCLength limits the size of a single message, far above any text document edited by hand
+const maxRPCLength = 64 << 20
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type lspPositionParams struct {
+	TextDocument lspTextDocument `json:"textDocument"`
+	Position     lspPosition     `json:"position"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspCodeAction struct {
+	Title       string `json:"title"`
+	Kind        string `json:"kind"`
+	IsPreferred bool   `json:"isPreferred,omitempty"`
+	Edit        struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+type lspCompletionItem struct {
+	Label    string      `json:"label"`
+	Kind     int         `json:"kind"`
+	Detail   string      `json:"detail"`
+	TextEdit lspTextEdit `json:"textEdit"`
+}
+
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+	lspCompletionValue = 12
+)
+
+func isASCIIAlnum(b byte) bool {
+	return '0' <= b && b <= '9' || 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z'
+}
+
+// utf16Len is the number of UTF-16 code units encoding the rune
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// utf16Column converts a byte offset within the line into a protocol character offset
+func utf16Column(line string, offset int) (column int) {
+	for _, r := range line[:offset] {
+		column += utf16Len(r)
+	}
+	return
+}
+
+// byteOffset converts a protocol character offset into a byte offset within the line
+func byteOffset(line string, column int) int {
+	units := 0
+	for i, r := range line {
+		if units >= column {
+			return i
+		}
+		units += utf16Len(r)
+	}
+	return len(line)
+}
+
+type lspServer struct {
+	out    io.Writer
+	errOut outFunc
+	docs   map[string][]string //lines by URI
+	known  []string
+	down   bool //shutdown requested
+}
+
+func (s *lspServer) send(message interface{}) {
+	body, _ := json.Marshal(message)
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// rangeOf converts the byte offsets of a word within the line
+func rangeOf(lines []string, lineNo int, start, end int) lspRange {
+	return lspRange{
+		lspPosition{lineNo, utf16Column(lines[lineNo], start)},
+		lspPosition{lineNo, utf16Column(lines[lineNo], end)},
+	}
+}
+
+// problemOf describes why the candidate is not a valid ID, the empty string means it is valid
+func problemOf(c ndocid.Candidate) (message string, severity int) {
+	switch {
+	case c.Err != nil:
+		return c.Err.Error(), lspSeverityError
+	case !c.Complete:
+		return "ID incomplete, needs further characters", lspSeverityWarning
+	}
+	return "", 0
+}
+
+func (s *lspServer) publishDiagnostics(uri string) {
+	lines := s.docs[uri]
+	diagnostics := []lspDiagnostic{}
+	for lineNo, line := range lines {
+		for _, c := range ndocid.FindCandidates(line) {
+			if message, severity := problemOf(c); message != "" {
+				diagnostics = append(diagnostics, lspDiagnostic{rangeOf(lines, lineNo, c.Start, c.End), severity, "ndocid", message})
+			}
+		}
+	}
+	s.send(rpcNotification{"2.0", "textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	}})
+}
+
+func describeID(id string, value uint64) string {
+	return fmt.Sprintf("**%s** (%s)\n\nDate: %s\n\nInteger: %d",
+		id, ndocid.VersionOf(id), time.Unix(int64(value), 0).Format(time.RFC1123Z), value)
+}
+
+func (s *lspServer) hover(p lspPositionParams) interface{} {
+	lines := s.docs[p.TextDocument.URI]
+	if p.Position.Line < 0 || p.Position.Line >= len(lines) {
+		return nil
+	}
+	line := lines[p.Position.Line]
+	offset := byteOffset(line, p.Position.Character)
+	for _, c := range ndocid.FindCandidates(line) {
+		if offset < c.Start || offset > c.End {
+			continue
+		}
+		contents := describeID(c.Text, c.Value)
+		if message, _ := problemOf(c); message != "" {
+			contents = fmt.Sprintf("**%s** is no valid ID: %s", c.Text, message)
+		}
+		return map[string]interface{}{
+			"contents": map[string]string{"kind": "markdown", "value": contents},
+			"range":    rangeOf(lines, p.Position.Line, c.Start, c.End),
+		}
+	}
+	return nil
+}
+
+func (s *lspServer) codeActions(uri string, r lspRange) []lspCodeAction {
+	lines := s.docs[uri]
+	actions := []lspCodeAction{}
+	for lineNo := r.Start.Line; lineNo <= r.End.Line && lineNo < len(lines); lineNo++ {
+		if lineNo < 0 {
+			continue
+		}
+		for _, candidate := range ndocid.FindCandidates(lines[lineNo]) {
+			tr := rangeOf(lines, lineNo, candidate.Start, candidate.End)
+			if tr.End.Line == r.Start.Line && tr.End.Character < r.Start.Character ||
+				tr.Start.Line == r.End.Line && tr.Start.Character > r.End.Character {
+				continue
+			}
+			for i, c := range ndocid.Suggest(candidate.Text) {
+				action := lspCodeAction{
+					Title:       fmt.Sprintf("Change to %s (%s at position %d)", c.ID, c.Kind, c.Position),
+					Kind:        "quickfix",
+					IsPreferred: i == 0 && c.Likely,
+				}
+				action.Edit.Changes = map[string][]lspTextEdit{uri: {{tr, c.ID}}}
+				actions = append(actions, action)
+			}
+		}
+	}
+	return actions
+}
+
+func (s *lspServer) completion(p lspPositionParams) []lspCompletionItem {
+	items := []lspCompletionItem{}
+	lines := s.docs[p.TextDocument.URI]
+	if p.Position.Line < 0 || p.Position.Line >= len(lines) {
+		return items
+	}
+	line := lines[p.Position.Line]
+	end := byteOffset(line, p.Position.Character)
+	start := end
+	for start > 0 && isASCIIAlnum(line[start-1]) {
+		start--
+	}
+	prefix := strings.ToUpper(line[start:end])
+	if prefix == "" || prefix[0] < '0' || prefix[0] > '9' {
+		return items
+	}
+	prefixRange := rangeOf(lines, p.Position.Line, start, end)
+	for _, id := range s.known {
+		if strings.HasPrefix(id, prefix) {
+			value, _, _ := ndocid.Decode(id)
+			items = append(items, lspCompletionItem{
+				Label:    id,
+				Kind:     lspCompletionValue,
+				Detail:   time.Unix(int64(value), 0).Format(time.RFC1123Z),
+				TextEdit: lspTextEdit{prefixRange, id},
+			})
+		}
+	}
+	return items
+}
+
+// addKnown accepts valid IDs for completion, invalid ones are reported and skipped
+func (s *lspServer) addKnown(ids []string) {
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if id == "" || strings.HasPrefix(id, "#") {
+			continue
+		}
+		if _, err, complete := ndocid.Decode(id); err != nil || !complete {
+			s.errOut("Ignoring known ID %s: not a valid complete ID", id)
+			continue
+		}
+		s.known = append(s.known, id)
+	}
+}
+
+// handle processes a single message, done is true when the client asked the server to exit
+func (s *lspServer) handle(req rpcRequest) (done bool, status int) {
+	var result interface{}
+	switch req.Method {
+	case "initialize":
+		var p struct {
+			InitializationOptions struct {
+				KnownIDs []string `json:"knownIDs"`
+			} `json:"initializationOptions"`
+		}
+		json.Unmarshal(req.Params, &p)
+		s.addKnown(p.InitializationOptions.KnownIDs)
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, //full
+				"hoverProvider":      true,
+				"codeActionProvider": map[string]interface{}{"codeActionKinds": []string{"quickfix"}},
+				"completionProvider": map[string]interface{}{},
+			},
+			"serverInfo": map[string]string{"name": "ndocid"},
+		}
+	case "shutdown":
+		s.down = true
+	case "exit":
+		if s.down {
+			return true, 0
+		}
+		return true, 1
+	case "textDocument/didOpen", "textDocument/didChange":
+		var p struct {
+			TextDocument   lspTextDocument   `json:"textDocument"`
+			ContentChanges []lspTextDocument `json:"contentChanges"`
+		}
+		json.Unmarshal(req.Params, &p)
+		text := p.TextDocument.Text
+		if n := len(p.ContentChanges); n > 0 {
+			text = p.ContentChanges[n-1].Text
+		}
+		s.docs[p.TextDocument.URI] = strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+		s.publishDiagnostics(p.TextDocument.URI)
+	case "textDocument/didClose":
+		var p lspPositionParams
+		json.Unmarshal(req.Params, &p)
+		delete(s.docs, p.TextDocument.URI)
+		s.send(rpcNotification{"2.0", "textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         p.TextDocument.URI,
+			"diagnostics": []lspDiagnostic{},
+		}})
+	case "textDocument/hover":
+		var p lspPositionParams
+		json.Unmarshal(req.Params, &p)
+		result = s.hover(p)
+	case "textDocument/codeAction":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+			Range        lspRange        `json:"range"`
+		}
+		json.Unmarshal(req.Params, &p)
+		result = s.codeActions(p.TextDocument.URI, p.Range)
+	case "textDocument/completion":
+		var p lspPositionParams
+		json.Unmarshal(req.Params, &p)
+		result = s.completion(p)
+	default:
+		if req.ID != nil {
+			var response rpcErrorResponse
+			response.JSONRPC, response.ID = "2.0", req.ID
+			response.Error.Code, response.Error.Message = rpcMethodNotFound, "Method not supported: "+req.Method
+			s.send(response)
+		}
+		return
+	}
+	if req.ID != nil {
+		s.send(rpcResponse{"2.0", req.ID, result})
+	}
+	return
+}
+
+func readRPC(in *bufio.Reader) (req rpcRequest, err error) {
+	header, err := textproto.NewReader(in).ReadMIMEHeader()
+	if err != nil {
+		return
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 || length > maxRPCLength {
+		return req, fmt.Errorf("Bad Content-Length: %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err = io.ReadFull(in, body); err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &req)
+	return
+}
+
+func runLSP(in io.Reader, out io.Writer, known []string, errOut outFunc) int {
+	s := &lspServer{out: out, errOut: errOut, docs: make(map[string][]string)}
+	s.addKnown(known)
+	reader := bufio.NewReader(in)
+	for {
+		req, err := readRPC(reader)
+		if err != nil {
+			if err != io.EOF {
+				errOut("%s", err)
+			}
+			return 1
+		}
+		if done, status := s.handle(req); done {
+			return status
+		}
+	}
+}
+
+func lsp(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("lsp", "", errOut)
+	knownFile := flags.String("known", "", "`file` listing known IDs offered for completion, one per line")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 0 {
+		errOut("Leftover arguments after flags (see ndocid lsp -h)")
+		return 2
+	}
+	var known []string
+	if *knownFile != "" {
+		content, err := os.ReadFile(*knownFile)
+		if err != nil {
+			errOut("%s", err)
+			return 1
+		}
+		known = strings.Split(string(content), "\n")
+	}
+	return runLSP(os.Stdin, os.Stdout, known, errOut)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// converse runs the server on the given messages and returns everything it sent
+func converse(t *testing.T, known []string, messages ...string) (status int, sent []map[string]interface{}) {
+	t.Helper()
+	var in, out bytes.Buffer
+	for _, m := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	status = runLSP(&in, &out, known, silentOut)
+	reader := bufio.NewReader(&out)
+	for {
+		var length int
+		if _, err := fmt.Fscanf(reader, "Content-Length: %d\r\n\r\n", &length); err != nil {
+			break
+		}
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		var message map[string]interface{}
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, message)
+	}
+	return
+}
+
+const lspDocument = `Contract 72639D77LD signed, see 52247CRMYT and 52247C. Version 2: 72639Z77LD8 ✓ 72639D77DL`
+
+func lspOpen(text string) string {
+	encoded, _ := json.Marshal(text)
+	return `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///notes.md","text":` + string(encoded) + `}}}`
+}
+
+func lspRequest(id int, method string, params string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"%s","params":%s}`, id, method, params)
+}
+
+func lspPositionAt(text, substring string) string {
+	column := len([]rune(text[:strings.Index(text, substring)])) //test document is within the Basic Multilingual Plane
+	return fmt.Sprintf(`{"textDocument":{"uri":"file:///notes.md"},"position":{"line":0,"character":%d}}`, column+1)
+}
+
+const lspExit = `{"jsonrpc":"2.0","method":"exit"}`
+
+func TestLSPLifecycle(t *testing.T) {
+	status, sent := converse(t, nil, lspRequest(1, "initialize", `{}`), lspRequest(2, "shutdown", `null`), lspExit)
+	if status != 0 || len(sent) != 2 {
+		t.Fatalf("expected clean exit after two responses but got status %d and %v", status, sent)
+	}
+	if _, ok := sent[0]["result"].(map[string]interface{})["capabilities"]; !ok {
+		t.Error("expected capabilities")
+	}
+	if status, _ := converse(t, nil, lspExit); status != 1 {
+		t.Error("expected status 1 for exit without shutdown")
+	}
+	_, sent = converse(t, nil, lspRequest(1, "unknown/method", `{}`), lspExit)
+	if _, ok := sent[0]["error"]; !ok {
+		t.Error("expected error for unknown method")
+	}
+}
+
+func TestLSPDiagnostics(t *testing.T) {
+	_, sent := converse(t, nil, lspOpen(lspDocument), lspExit)
+	diagnostics := sent[0]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	var flagged []string
+	for _, d := range diagnostics {
+		r := d.(map[string]interface{})["range"].(map[string]interface{})
+		start := int(r["start"].(map[string]interface{})["character"].(float64))
+		end := int(r["end"].(map[string]interface{})["character"].(float64))
+		flagged = append(flagged, string([]rune(lspDocument)[start:end]))
+	}
+	if strings.Join(flagged, ",") != "52247CRMYT,52247C,72639D77DL" {
+		t.Errorf("unexpected diagnostics for %v", flagged)
+	}
+}
+
+func TestLSPHover(t *testing.T) {
+	_, sent := converse(t, nil, lspOpen(lspDocument),
+		lspRequest(1, "textDocument/hover", lspPositionAt(lspDocument, "72639D77LD")),
+		lspRequest(2, "textDocument/hover", lspPositionAt(lspDocument, "Contract")),
+		lspExit)
+	contents := sent[1]["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"].(string)
+	if !strings.Contains(contents, "1552572000") || !strings.Contains(contents, "2019") {
+		t.Errorf("expected date and integer but got %q", contents)
+	}
+	if sent[2]["result"] != nil {
+		t.Error("expected no hover outside IDs")
+	}
+}
+
+func TestLSPQuickFix(t *testing.T) {
+	at := strings.Index(lspDocument, "72639D77DL")
+	params := fmt.Sprintf(`{"textDocument":{"uri":"file:///notes.md"},"range":{"start":{"line":0,"character":%[1]d},"end":{"line":0,"character":%[1]d}}}`,
+		len([]rune(lspDocument[:at])))
+	_, sent := converse(t, nil, lspOpen(lspDocument), lspRequest(1, "textDocument/codeAction", params), lspExit)
+	actions := sent[1]["result"].([]interface{})
+	if len(actions) == 0 {
+		t.Fatal("expected quick fixes")
+	}
+	first := actions[0].(map[string]interface{})
+	edits := first["edit"].(map[string]interface{})["changes"].(map[string]interface{})["file:///notes.md"].([]interface{})
+	if edits[0].(map[string]interface{})["newText"] != "72639D77LD" || first["isPreferred"] != true {
+		t.Errorf("expected preferred fix to 72639D77LD but got %v", first)
+	}
+}
+
+func TestLSPCompletion(t *testing.T) {
+	text := "see 7263"
+	params := `{"textDocument":{"uri":"file:///notes.md"},"position":{"line":0,"character":8}}`
+	_, sent := converse(t, []string{"72639D77LD", "52247CRMTY", "72639D77DL", "# comment"},
+		lspOpen(text), lspRequest(1, "textDocument/completion", params), lspExit)
+	items := sent[1]["result"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["label"] != "72639D77LD" {
+		t.Errorf("expected single valid known ID but got %v", items)
+	}
+	_, sent = converse(t, nil, lspRequest(1, "initialize", `{"initializationOptions":{"knownIDs":["72639Z77LD8"]}}`),
+		lspOpen(text), lspRequest(2, "textDocument/completion", params), lspExit)
+	items = sent[2]["result"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["label"] != "72639Z77LD8" {
+		t.Errorf("expected known ID from initialization options but got %v", items)
+	}
+}
+
+func TestLSPBadInput(t *testing.T) {
+	for _, line := range []int{-1, 5} {
+		position := fmt.Sprintf(`{"textDocument":{"uri":"file:///notes.md"},"position":{"line":%d,"character":0}}`, line)
+		lines := fmt.Sprintf(`{"textDocument":{"uri":"file:///notes.md"},"range":{"start":{"line":%[1]d,"character":0},"end":{"line":%[1]d,"character":0}}}`, line)
+		_, sent := converse(t, nil, lspOpen(lspDocument),
+			lspRequest(1, "textDocument/hover", position),
+			lspRequest(2, "textDocument/codeAction", lines),
+			lspRequest(3, "textDocument/completion", position),
+			lspExit)
+		if len(sent) != 4 || sent[1]["result"] != nil || len(sent[2]["result"].([]interface{})) != 0 || len(sent[3]["result"].([]interface{})) != 0 {
+			t.Errorf("line %d: expected empty results but got %v", line, sent)
+		}
+	}
+
+	for _, length := range []string{"-1", "x", "1000000000000"} {
+		in := strings.NewReader("Content-Length: " + length + "\r\n\r\n{}")
+		if status := runLSP(in, io.Discard, nil, silentOut); status != 1 {
+			t.Errorf("Content-Length %s: expected status 1 but got %d", length, status)
+		}
+	}
+}
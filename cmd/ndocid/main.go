@@ -4,11 +4,17 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/n2code/ndocid"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd.run(os.Args[2:], sysOut, sysErrLineOut))
+		}
+	}
 	status := run(getParametersFromFlags(), sysOut, sysErrLineOut)
 	os.Exit(status)
 }
@@ -26,13 +32,50 @@ func getParametersFromFlags() (params parameters) {
 	flag.BoolVar(&params.now, "n", false, "NOW-MODE: Generate ID from current date and time of this machine.")
 	flag.StringVar(&params.bitstring, "b", "", "BITSTRING-MODE: Generate ID from string of bits, e.g. `\"00010110 11011011\"`.\n  Spaces, tabs, underscores and leading zeros are being dropped.\n  The maximum length is 64 bits.\n  Bad input will result in an exit code greater than 0.")
 	flag.Uint64Var(&params.number, "i", 0, "INTEGER-MODE: Generate ID from number, e.g. `42`.\n  Accepts any positive decimal number that can fit in an unsigned 64 bit integer.\n  Exit code greater than 0 if input exceeds range.")
-	flag.BoolVar(&ndocid.Verbose, "v", false, "Verbose option: Generate more human-readable output.\n  Explains algorithm in MODEs that generate IDs.\n  Provides possible source representations when reversing is successful.")
+	flag.BoolVar(&ndocid.Verbose, "v", false, "Verbose option: Generate more human-readable output.\n  Explains algorithm in MODEs that generate IDs.\n  Explains the checks when reversing and points at probable typos if they fail.\n  Provides possible source representations when reversing is successful.")
+	flag.BoolVar(&params.version2, "2", false, "Version option: Generate version 2 IDs with stronger checks (see README).\n  Marked by Z in the 6th position, e.g. 72639Z77LD8. Reversing accepts both versions.")
 	flag.StringVar(&params.reverse, "r", "", "REVERSING/CHECK-MODE: Validates given ID, e.g. `72639D77LD`.\n  Exit code 0: Valid full ID\n  Exit code 1: Invalid ID\n  Exit code 4: Plausible partial ID (beginning), needs further digits\n  The first line returned is OK / ERROR / PARTIAL for exit codes 0 / 1 / 4.")
+	flag.StringVar(&params.spoken, "s", "", "SPOKEN-MODE: Validates ID dictated using a spelling alphabet, e.g. `\"nine six eight two two lima nine india papa delta\"`.\n  Words are understood in the alphabet given by -spell or else in any available one.\n  Exit codes and first line returned as in REVERSING/CHECK-MODE, the second line is the ID understood.")
+	flag.StringVar(&params.spell, "spell", "", "Spelling option: Output generated IDs as words of the given spelling `alphabet`: "+strings.Join(spellingAlphabetNames(), ", ")+".\n  Selects the alphabet understood in SPOKEN-MODE.")
+	flag.StringVar(&params.fromWords, "w", "", "WORDS-MODE: Validates words of the word encoding, e.g. `inch-metal-insect-island-audio`.\n  The first three letters of each word suffice.\n  Exit code 0 and OK followed by the ID (version 2 with -2) if valid, exit code 1 and INVALID if not.")
+	flag.BoolVar(&params.words, "words", false, "Words option: Output generated IDs as words which are easier to remember, each standing for a byte,\n  followed by a check word. With -r the words of a valid ID are returned in the second line.")
+	flag.StringVar(&params.fromDigits, "k", "", "KEYPAD-MODE: Validates ID encoded in digits only, e.g. `7263956540973`.\n  Exit codes and first line returned as in REVERSING/CHECK-MODE, followed by the ID (version 2 with -2) if valid.")
+	flag.BoolVar(&params.digits, "digits", false, "Digits option: Output generated IDs in digits only for telephone keypads.\n  The first five digits equal those of the ID, followed by two check digits and the remaining bits in decimal.\n  With -r the digits of a valid ID are returned in the second line.")
+	flag.BoolVar(&params.sortable, "sortable", false, "Sortable option: Output generated IDs in the sortable layout of fixed length, e.g. Z2222223HANQM2V, whose string order is chronological.\n  With -r the sortable ID of a valid ID is returned in the second line.\n  Reversing accepts sortable IDs, which are marked by a leading Z, and returns the ID (version 2 with -2) in the second line.")
+	flag.StringVar(&params.childOf, "c", "", "CHILD-MODE: Generate reference to the next revision or attachment of the given document, e.g. `96822L9IPD-R2C`.\n  The next sibling is returned for a child of the same kind, e.g. 96822L9IPD-R3A, else its first child of that kind.\n  References have a check character for every child and are validated by REVERSING/CHECK-MODE as well.")
+	flag.StringVar(&params.kind, "kind", "", "Kind option: `kind` of child generated in CHILD-MODE: revision (default) or attachment.")
+	flag.IntVar(&params.length, "len", 0, "Length option: Generate version 1 IDs of the given `length` from 6 to 17, padded with 2s, e.g. 72639D77LD22.\n  Exit code 2 if the length is out of range or the ID longer. With -r shorter IDs are partial, longer ones and version 2 IDs invalid.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), commandUsage())
+	}
 	flag.Parse()
 	params.flagsSet = flag.NFlag()
 	if ndocid.Verbose {
 		params.flagsSet-- //verbose does not count
 	}
+	if params.version2 {
+		params.flagsSet-- //version does not count either
+	}
+	if params.spell != "" {
+		params.flagsSet-- //neither does the spelling alphabet
+	}
+	if params.words {
+		params.flagsSet-- //nor words
+	}
+	if params.digits {
+		params.flagsSet-- //nor digits
+	}
+	if params.sortable {
+		params.flagsSet-- //nor the sortable layout
+	}
+	if params.kind != "" {
+		params.flagsSet-- //nor the kind of child
+	}
+	if params.length != 0 {
+		params.flagsSet-- //nor the length
+	}
 	if flag.NArg() != 0 {
 		params.leftoverArgs = true
 	}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/n2code/ndocid"
+	"github.com/n2code/ndocid/ndocidtemplate"
+)
+
+// renderData is the data passed to templates: the issued ID and the optional JSON data as .Data
+type renderData struct {
+	ID    string
+	Value uint64
+	Date  time.Time
+	Data  interface{}
+}
+
+// parseTemplate reads the template file, HTML templates escape their output
+func parseTemplate(path string, asHTML bool) (execute func(w io.Writer, data interface{}) error, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(path)
+	if asHTML {
+		tmpl, err := htmltemplate.New(name).Funcs(ndocidtemplate.HTMLFuncMap).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+		return tmpl.Execute, nil
+	}
+	tmpl, err := template.New(name).Funcs(ndocidtemplate.FuncMap).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Execute, nil
+}
+
+func render(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("render", "TEMPLATE", errOut)
+	output := flags.String("o", "", "write the document to `file` instead of standard output and print the ID")
+	dataPath := flags.String("data", "", "JSON `file` whose content is available as .Data")
+	html := flags.Bool("html", false, "escape like html/template, default for templates ending in .html or .htm")
+	state := flags.String("state", defaultIssuerState(), "`file` remembering the last ID issued, shared with other commands issuing IDs")
+	version2 := flags.Bool("2", false, "generate a version 2 ID")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 1 {
+		errOut("Exactly one template expected (see ndocid render -h)")
+		return 2
+	}
+	templatePath := flags.Arg(0)
+	ext := strings.ToLower(filepath.Ext(templatePath))
+	asHTML := *html || ext == ".html" || ext == ".htm"
+
+	execute, err := parseTemplate(templatePath, asHTML)
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	var data renderData
+	if *dataPath != "" {
+		content, err := os.ReadFile(*dataPath)
+		if err != nil {
+			errOut("%s", err)
+			return 1
+		}
+		if err := json.Unmarshal(content, &data.Data); err != nil {
+			errOut("Bad JSON in %s: %s", *dataPath, err)
+			return 1
+		}
+	}
+	value, err := newIssuer(*state).issue(uint64(time.Now().Unix()))
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	version := ndocid.V1
+	if *version2 {
+		version = ndocid.V2
+	}
+	data.ID, data.Value, data.Date = version.Encode(value), value, time.Unix(int64(value), 0)
+
+	//the document is written only if rendering succeeded entirely
+	var document bytes.Buffer
+	if err := execute(&document, data); err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	if *output == "" {
+		out("%s", document.String())
+		return 0
+	}
+	if err := os.WriteFile(*output, document.Bytes(), 0644); err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	out("%s\n", data.ID)
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "issued")
+	letter := filepath.Join(dir, "letter.txt")
+	os.WriteFile(letter, []byte(`Ref. {{ ndocidFormat " " .ID }} ({{ ndocidDecode .ID }}) for {{ .Data.name }}`), 0644)
+	data := filepath.Join(dir, "data.json")
+	os.WriteFile(data, []byte(`{"name": "<Jane>"}`), 0644)
+
+	var first, second string
+	if status := render([]string{"-state", state, "-data", data, letter}, spyIntoString(&first), silentOut); status != 0 {
+		t.Fatalf("rendering failed with status %d", status)
+	}
+	if !regexp.MustCompile(`^Ref\. \d{5} \w{5} \(\d+\) for <Jane>$`).MatchString(first) {
+		t.Errorf("unexpected document %q", first)
+	}
+	render([]string{"-state", state, "-data", data, letter}, spyIntoString(&second), silentOut)
+	if first == second {
+		t.Error("expected a fresh ID for every document")
+	}
+
+	page := filepath.Join(dir, "page.html")
+	os.WriteFile(page, []byte(`<p>{{ .Data.name }}</p>{{ ndocidBarcode .ID }}`), 0644)
+	document := filepath.Join(dir, "out.html")
+	var id string
+	if status := render([]string{"-state", state, "-data", data, "-o", document, page}, spyIntoString(&id), silentOut); status != 0 {
+		t.Fatalf("rendering failed with status %d", status)
+	}
+	content, _ := os.ReadFile(document)
+	if !strings.HasPrefix(string(content), "<p>&lt;Jane&gt;</p><svg") || len(strings.TrimSpace(id)) != 10 {
+		t.Errorf("expected escaped HTML with barcode and ID printed, got %q and %q", content, id)
+	}
+
+	numbered := filepath.Join(dir, "numbered.txt")
+	os.WriteFile(numbered, []byte(`{{ ndocid .Data.n }} {{ ndocid .Data.s }}`), 0644)
+	os.WriteFile(data, []byte(`{"n": 1552572000, "s": "1552572000"}`), 0644)
+	var ids string
+	if status := render([]string{"-state", state, "-data", data, numbered}, spyIntoString(&ids), silentOut); status != 0 || ids != "72639D77LD 72639D77LD" {
+		t.Errorf("expected IDs of JSON numbers and strings but got %q (status %d)", ids, status)
+	}
+
+	broken := filepath.Join(dir, "broken.txt")
+	os.WriteFile(broken, []byte(`{{ ndocidDecode "72639D77DL" }}`), 0644)
+	if status := render([]string{"-state", state, "-o", filepath.Join(dir, "broken.out"), broken}, silentOut, silentOut); status != 1 {
+		t.Errorf("expected status 1 but got %d", status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.out")); err == nil {
+		t.Error("no document expected if rendering fails")
+	}
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/n2code/ndocid"
+)
+
+//go:embed openapi.json
+var openAPIDescription []byte
+
+// page checks IDs while typing and generates new ones using the API, it needs no resources from elsewhere
+//
+//go:embed page.html
+var page []byte
+
+// operation is a single API request, either given as query parameters or as element of a batch
+type operation struct {
+	Op        string  `json:"op"`
+	ID        string  `json:"id,omitempty"`
+	Int       *uint64 `json:"int,omitempty"`
+	Date      string  `json:"date,omitempty"`
+	Bitstring string  `json:"bitstring,omitempty"`
+	Now       bool    `json:"now,omitempty"`
+	Version   int     `json:"version,omitempty"`
+}
+
+type result struct {
+	ID          string       `json:"id,omitempty"`
+	Status      string       `json:"status,omitempty"`
+	Value       *uint64      `json:"value,omitempty"`
+	Date        string       `json:"date,omitempty"`
+	Version     int          `json:"version,omitempty"`
+	Suggestions []suggestion `json:"suggestions,omitempty"`
+	Error       *apiError    `json:"error,omitempty"`
+}
+
+type suggestion struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Position int    `json:"position"`
+	Likely   bool   `json:"likely"`
+}
+
+type apiError struct {
+	status   int
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Position int    `json:"position,omitempty"`
+}
+
+func badRequest(format string, msg ...interface{}) *apiError {
+	return &apiError{http.StatusBadRequest, "bad_request", fmt.Sprintf(format, msg...), 0}
+}
+
+// decodeError converts the error of a rejected ID
+func decodeError(err error) *apiError {
+	e := &apiError{http.StatusUnprocessableEntity, "invalid_id", err.Error(), 0}
+	var decodeErr *ndocid.DecodeError
+	if errors.As(err, &decodeErr) {
+		e.Position = decodeErr.Position
+	}
+	return e
+}
+
+func decodedResult(id string, value uint64) result {
+	return result{
+		ID:      id,
+		Status:  ndocid.Complete.String(),
+		Value:   &value,
+		Date:    time.Unix(int64(value), 0).Format(time.RFC3339),
+		Version: int(ndocid.VersionOf(id)),
+	}
+}
+
+func (o operation) execute() (result, *apiError) {
+	//overlong input would only make decoding and suggesting expensive
+	if n := utf8.RuneCountInString(o.ID); n > ndocid.MaxIDLength {
+		return result{}, badRequest("ID of %d characters exceeds the maximum of %d", n, ndocid.MaxIDLength)
+	}
+	switch o.Op {
+	case "encode":
+		version := ndocid.V1
+		switch o.Version {
+		case 0, 1:
+		case 2:
+			version = ndocid.V2
+		default:
+			return result{}, badRequest("Unknown version: %d", o.Version)
+		}
+		var number uint64
+		inputs := 0
+		if o.Int != nil {
+			number = *o.Int
+			inputs++
+		}
+		if o.Date != "" {
+			var err error
+			if number, err = ndocid.ParseDatetime(o.Date); err != nil {
+				return result{}, badRequest("%s", err)
+			}
+			inputs++
+		}
+		if o.Bitstring != "" {
+			var err error
+			if number, err = ndocid.ParseBitstring(o.Bitstring); err != nil {
+				return result{}, badRequest("%s", err)
+			}
+			inputs++
+		}
+		if o.Now {
+			number = uint64(time.Now().Unix())
+			inputs++
+		}
+		if inputs != 1 {
+			return result{}, badRequest("Exactly one of int, date, bitstring or now required")
+		}
+		return decodedResult(version.Encode(number), number), nil
+	case "decode", "validate":
+		value, err, complete := ndocid.Decode(o.ID)
+		switch {
+		case o.Op == "validate" && err != nil:
+			return result{ID: o.ID, Status: ndocid.Invalid.String(), Error: decodeError(err)}, nil
+		case o.Op == "validate" && !complete:
+			return result{ID: o.ID, Status: ndocid.Partial.String()}, nil
+		case err != nil:
+			return result{}, decodeError(err)
+		case !complete:
+			return result{}, &apiError{http.StatusUnprocessableEntity, "partial_id", "ID incomplete, needs further characters", 0}
+		}
+		return decodedResult(o.ID, value), nil
+	case "suggest":
+		r := result{ID: o.ID, Suggestions: []suggestion{}}
+		for _, c := range ndocid.Suggest(o.ID) {
+			r.Suggestions = append(r.Suggestions, suggestion{c.ID, c.Kind.String(), c.Position, c.Likely})
+		}
+		return r, nil
+	}
+	return result{}, badRequest("Unknown operation: %s", o.Op)
+}
+
+func operationFromQuery(op string, r *http.Request) (o operation, e *apiError) {
+	q := r.URL.Query()
+	o = operation{Op: op, ID: q.Get("id"), Date: q.Get("date"), Bitstring: q.Get("bitstring")}
+	if s := q.Get("int"); s != "" {
+		number, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return o, badRequest("Bad integer: %s", s)
+		}
+		o.Int = &number
+	}
+	if s := q.Get("now"); s != "" {
+		now, err := strconv.ParseBool(s)
+		if err != nil {
+			return o, badRequest("Bad boolean: %s", s)
+		}
+		o.Now = now
+	}
+	if s := q.Get("version"); s != "" {
+		version, err := strconv.Atoi(s)
+		if err != nil {
+			return o, badRequest("Bad version: %s", s)
+		}
+		o.Version = version
+	}
+	if _, given := q["id"]; op != "encode" && !given {
+		return o, badRequest("Missing parameter: id")
+	}
+	return
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, struct {
+		Error *apiError `json:"error"`
+	}{e})
+}
+
+type apiLimits struct {
+	maxBody  int64
+	maxBatch int
+}
+
+func newAPIHandler(limits apiLimits) http.Handler {
+	mux := http.NewServeMux()
+	for _, op := range []string{"encode", "decode", "validate", "suggest"} {
+		op := op
+		mux.HandleFunc("/v1/"+op, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				writeError(w, &apiError{http.StatusMethodNotAllowed, "method_not_allowed", "Use GET", 0})
+				return
+			}
+			o, e := operationFromQuery(op, r)
+			if e == nil {
+				var res result
+				if res, e = o.execute(); e == nil {
+					writeJSON(w, http.StatusOK, res)
+					return
+				}
+			}
+			writeError(w, e)
+		})
+	}
+	mux.HandleFunc("/v1/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, &apiError{http.StatusMethodNotAllowed, "method_not_allowed", "Use POST", 0})
+			return
+		}
+		var batch struct {
+			Operations []operation `json:"operations"`
+		}
+		body, e := readBody(r, limits.maxBody)
+		if e != nil {
+			writeError(w, e)
+			return
+		}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeError(w, badRequest("Bad JSON: %s", err))
+			return
+		}
+		if len(batch.Operations) > limits.maxBatch {
+			writeError(w, &apiError{http.StatusRequestEntityTooLarge, "too_large", fmt.Sprintf("Batch exceeds %d operations", limits.maxBatch), 0})
+			return
+		}
+		results := make([]result, len(batch.Operations))
+		for i, o := range batch.Operations {
+			res, e := o.execute()
+			if e != nil {
+				res = result{ID: o.ID, Error: e}
+			}
+			results[i] = res
+		}
+		writeJSON(w, http.StatusOK, struct {
+			Results []result `json:"results"`
+		}{results})
+	})
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPIDescription)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+			return
+		}
+		writeError(w, &apiError{http.StatusNotFound, "not_found", "No such endpoint, see /openapi.json", 0})
+	})
+	return limitBodies(limits.maxBody, mux)
+}
+
+func bodyTooLarge(limit int64) *apiError {
+	return &apiError{http.StatusRequestEntityTooLarge, "too_large", fmt.Sprintf("Request body exceeds %d bytes", limit), 0}
+}
+
+// readBody reads at most one byte more than the limit to tell whether the body exceeds it
+func readBody(r *http.Request, limit int64) ([]byte, *apiError) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, badRequest("Cannot read body: %s", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, bodyTooLarge(limit)
+	}
+	return body, nil
+}
+
+// limitBodies rejects requests to any endpoint announcing a larger body and cuts off the others at the limit
+func limitBodies(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeError(w, bodyTooLarge(limit))
+			return
+		}
+		//one byte more is left for readBody to detect the excess
+		r.Body = http.MaxBytesReader(w, r.Body, limit+1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func serve(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("serve", "", errOut)
+	addr := flags.String("addr", "localhost:8080", "TCP `address` to listen on")
+	socket := flags.String("socket", "", "Unix socket `path` to listen on instead of TCP")
+	maxBody := flags.Int64("max-body", 1<<20, "maximum size of request bodies to any endpoint in `bytes`")
+	maxBatch := flags.Int("max-batch", 10000, "maximum number of operations per batch request")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 0 {
+		errOut("Leftover arguments after flags (see ndocid serve -h)")
+		return 2
+	}
+
+	network, address := "tcp", *addr
+	if *socket != "" {
+		network, address = "unix", *socket
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	server := &http.Server{
+		Handler:           newAPIHandler(apiLimits{*maxBody, *maxBatch}),
+		ReadHeaderTimeout: 10 * time.Second,
+		MaxHeaderBytes:    1 << 16,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	out("Serving on %s %s\n", network, listener.Addr())
+	if network == "tcp" {
+		out("Browser page at http://%s/\n", listener.Addr())
+	}
+
+	select {
+	case err = <-served:
+		errOut("%s", err)
+		return 1
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	out("Shut down gracefully\n")
+	return 0
+}
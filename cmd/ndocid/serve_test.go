@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func request(method, target, body string) (int, map[string]interface{}) {
+	handler := newAPIHandler(apiLimits{maxBody: 256, maxBatch: 3})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	return recorder.Code, response
+}
+
+func TestServeEncodeDecode(t *testing.T) {
+	assert := func(target string, expStatus int, expField, expValue string) {
+		t.Helper()
+		status, response := request(http.MethodGet, target, "")
+		if status != expStatus {
+			t.Errorf("%s: expected status %d but got %d", target, expStatus, status)
+		}
+		if errObj, isError := response["error"].(map[string]interface{}); isError && expStatus != http.StatusOK {
+			response = errObj
+		}
+		if got := response[expField]; got != expValue {
+			t.Errorf("%s: expected %s %q but got %v", target, expField, expValue, got)
+		}
+	}
+	assert("/v1/encode?int=4133980800", http.StatusOK, "id", "52247CRMTY")
+	assert("/v1/encode?bitstring=01011101011100111001011111010110", http.StatusOK, "id", "68495LTTOD")
+	assert("/v1/encode?int=1552572000&version=2", http.StatusOK, "id", "72639Z77LD8")
+	assert("/v1/encode?int=1&now=true", http.StatusBadRequest, "code", "bad_request")
+	assert("/v1/encode?int=x", http.StatusBadRequest, "code", "bad_request")
+	assert("/v1/encode?int=1&version=3", http.StatusBadRequest, "code", "bad_request")
+	assert("/v1/decode?id=52247CRMTY", http.StatusOK, "status", "OK")
+	assert("/v1/decode?id=52247CRMYT", http.StatusUnprocessableEntity, "code", "invalid_id")
+	assert("/v1/decode?id=52247", http.StatusUnprocessableEntity, "code", "partial_id")
+	assert("/v1/suggest?id="+strings.Repeat("9", 21), http.StatusBadRequest, "code", "bad_request")
+	assert("/v1/decode", http.StatusBadRequest, "code", "bad_request")
+	assert("/v1/validate?id=52247", http.StatusOK, "status", "PARTIAL")
+	assert("/v1/validate?id=52247CRMTY", http.StatusOK, "status", "OK")
+	assert("/v1/validate?id=52274", http.StatusOK, "status", "INVALID")
+	assert("/v1/suggest?id=52247CRMYT", http.StatusOK, "id", "52247CRMYT")
+	assert("/v1/unknown", http.StatusNotFound, "code", "not_found")
+	assert("/v1/batch", http.StatusMethodNotAllowed, "code", "method_not_allowed")
+}
+
+func TestServeDecodeErrorPosition(t *testing.T) {
+	_, response := request(http.MethodGet, "/v1/decode?id=52X47CRMTY", "")
+	errObj, _ := response["error"].(map[string]interface{})
+	if errObj["position"] != 3.0 {
+		t.Errorf("expected error at position 3 but got %v", errObj["position"])
+	}
+}
+
+func TestServeSuggest(t *testing.T) {
+	_, response := request(http.MethodGet, "/v1/suggest?id=52247CRMYT", "")
+	suggestions, _ := response["suggestions"].([]interface{})
+	if len(suggestions) == 0 {
+		t.Fatal("expected suggestions")
+	}
+	for _, s := range suggestions {
+		if s := s.(map[string]interface{}); s["id"] == "52247CRMTY" {
+			if s["kind"] != "transposition" || s["position"] != 9.0 || s["likely"] != true {
+				t.Errorf("expected likely transposition at position 9 but got %v", s)
+			}
+			return
+		}
+	}
+	t.Error("expected 52247CRMTY among suggestions")
+}
+
+func TestServeBatch(t *testing.T) {
+	status, response := request(http.MethodPost, "/v1/batch",
+		`{"operations":[{"op":"encode","int":4133980800},{"op":"decode","id":"52247CRMYT"},{"op":"validate","id":"52247"}]}`)
+	if status != http.StatusOK {
+		t.Fatalf("expected status 200 but got %d", status)
+	}
+	results, _ := response["results"].([]interface{})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results but got %d", len(results))
+	}
+	if id := results[0].(map[string]interface{})["id"]; id != "52247CRMTY" {
+		t.Errorf("expected encoded ID but got %v", id)
+	}
+	if _, failed := results[1].(map[string]interface{})["error"]; !failed {
+		t.Error("expected failed decoding")
+	}
+	if s := results[2].(map[string]interface{})["status"]; s != "PARTIAL" {
+		t.Errorf("expected partial ID but got %v", s)
+	}
+
+	assertStatus := func(body string, exp int) {
+		t.Helper()
+		if status, _ := request(http.MethodPost, "/v1/batch", body); status != exp {
+			t.Errorf("expected status %d but got %d", exp, status)
+		}
+	}
+	assertStatus(`{"operations":[`, http.StatusBadRequest)
+	if _, response := request(http.MethodPost, "/v1/batch", `{"operations":[{"op":"suggest","id":"`+strings.Repeat("9", 100)+`"}]}`); !strings.Contains(fmt.Sprint(response), "bad_request") {
+		t.Errorf("expected overlong ID to be rejected in batch but got %v", response)
+	}
+	assertStatus(`{"operations":[{"op":"x"},{"op":"x"},{"op":"x"},{"op":"x"}]}`, http.StatusRequestEntityTooLarge)
+	assertStatus(`{"operations":[`+strings.Repeat(`{"op":"decode","id":"52247CRMTY"},`, 10)+`]}`, http.StatusRequestEntityTooLarge)
+}
+
+func TestServeBodyLimit(t *testing.T) {
+	//unknown length: read up to the limit and one byte more
+	handler := newAPIHandler(apiLimits{maxBody: 256, maxBatch: 3})
+	recorder := httptest.NewRecorder()
+	chunked := httptest.NewRequest(http.MethodPost, "/v1/batch", io.MultiReader(strings.NewReader(`{"operations":[]}`), strings.NewReader(strings.Repeat(" ", 240))))
+	chunked.ContentLength = -1
+	handler.ServeHTTP(recorder, chunked)
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d but got %d", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+	//exactly at the limit
+	if status, _ := request(http.MethodPost, "/v1/batch", `{"operations":[]}`+strings.Repeat(" ", 239)); status != http.StatusOK {
+		t.Errorf("expected body at the limit to be accepted but got %d", status)
+	}
+	for _, target := range []string{"/v1/decode?id=52247CRMTY", "/openapi.json", "/"} {
+		if status, _ := request(http.MethodGet, target, strings.Repeat(" ", 257)); status != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected large body to be rejected but got %d", target, status)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	status, response := request(http.MethodGet, "/openapi.json", "")
+	if status != http.StatusOK || response["openapi"] == nil {
+		t.Error("expected OpenAPI description")
+	}
+}
+
+func TestServeHelp(t *testing.T) {
+	var errOut string
+	if status := serve([]string{"-h"}, silentOut, spyIntoString(&errOut)); status != 0 {
+		t.Errorf("expected status 0 but got %d", status)
+	}
+	if !strings.Contains(errOut, "-max-batch") {
+		t.Error("expected usage")
+	}
+	if status := serve([]string{"-unknown"}, silentOut, silentOut); status != 2 {
+		t.Errorf("expected status 2 but got %d", status)
+	}
+}
+
+func TestServePage(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newAPIHandler(apiLimits{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected HTML page but got status %d", recorder.Code)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, "v1/") || strings.Contains(body, "http://") || strings.Contains(body, "https://") {
+		t.Error("expected self-contained page using the API")
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/n2code/ndocid"
+)
+
+type rename struct {
+	from, to string
+}
+
+type stampOptions struct {
+	template string
+	date     string //explicit date instead of the modification times
+	version  ndocid.Version
+}
+
+// expandPatterns resolves globs and drops directories, every pattern must match at least one file
+func expandPatterns(patterns []string) (files []string, err error) {
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Bad pattern %s: %s", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("No such file: %s", pattern)
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err != nil || info.IsDir() || seen[m] {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	return
+}
+
+func renderStampName(template, path, id string, value uint64) string {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return strings.NewReplacer(
+		"{id}", id,
+		"{value}", strconv.FormatUint(value, 10),
+		"{name}", strings.TrimSuffix(base, ext),
+		"{ext}", ext,
+	).Replace(template)
+}
+
+// planStamps decides the new name of every file. Every ID is used once, files are processed ordered by time and path
+// so that collisions, i.e. files of the same second or existing targets, deterministically get the next free second.
+// Such IDs no longer match the files' dates, every one of them is reported by warn.
+func planStamps(files []string, opts stampOptions, warn outFunc) (plan []rename, err error) {
+	if !strings.Contains(opts.template, "{id}") {
+		return nil, fmt.Errorf("Template lacks {id}: %s", opts.template)
+	}
+	type stampable struct {
+		path  string
+		value uint64
+	}
+	var stampables []stampable
+	for _, path := range files {
+		var value uint64
+		if opts.date != "" {
+			if value, err = ndocid.ParseDatetime(opts.date); err != nil {
+				return
+			}
+		} else {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.ModTime().Unix() < 0 {
+				return nil, fmt.Errorf("Modification time before 1970: %s", path)
+			}
+			value = uint64(info.ModTime().Unix())
+		}
+		stampables = append(stampables, stampable{path, value})
+	}
+	sort.SliceStable(stampables, func(a, b int) bool {
+		if stampables[a].value != stampables[b].value {
+			return stampables[a].value < stampables[b].value
+		}
+		return stampables[a].path < stampables[b].path
+	})
+
+	taken := make(map[uint64]bool)
+	for _, s := range stampables {
+		for value := s.value; ; value++ {
+			name := renderStampName(opts.template, s.path, opts.version.Encode(value), value)
+			if strings.ContainsRune(name, filepath.Separator) || name == "" || name == "." || name == ".." {
+				return nil, fmt.Errorf("Template yields no plain file name: %s", name)
+			}
+			target := filepath.Join(filepath.Dir(s.path), name)
+			if _, err := os.Lstat(target); taken[value] || err == nil {
+				continue
+			}
+			taken[value] = true
+			if value != s.value {
+				warn("%s gets the ID of %d seconds later, %s, since the others are taken", s.path, value-s.value, opts.version.Encode(value))
+			}
+			plan = append(plan, rename{s.path, target})
+			break
+		}
+	}
+	return
+}
+
+// applyStamps records every rename in the journal before renaming the file so that a crash never loses an undo record,
+// the entry is dropped again if the rename fails
+func applyStamps(plan []rename, journalPath string, out outFunc) error {
+	journal, err := os.OpenFile(journalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	for _, r := range plan {
+		from, err := filepath.Abs(r.from)
+		if err != nil {
+			return err
+		}
+		to, err := filepath.Abs(r.to)
+		if err != nil {
+			return err
+		}
+		size, err := journal.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := journal.WriteString(journalEntry(rename{to, from})); err != nil {
+			return err
+		}
+		if err := journal.Sync(); err != nil {
+			return err
+		}
+		if err := os.Rename(from, to); err != nil {
+			journal.Truncate(size)
+			return err
+		}
+		out("%s -> %s\n", r.from, r.to)
+	}
+	return nil
+}
+
+// undoStamps reverts the renames of the journal in reverse order and removes it. The last entry may not have been
+// carried out if stamping was interrupted, it is dropped if only the original file exists.
+func undoStamps(journalPath string, dryRun bool, out outFunc) error {
+	journal, err := os.Open(journalPath)
+	if err != nil {
+		return err
+	}
+	var undo []rename
+	scanner := bufio.NewScanner(journal)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		r, ok := parseJournalEntry(scanner.Text())
+		if !ok {
+			journal.Close()
+			return fmt.Errorf("Bad journal entry in line %d of %s", lineNo, journalPath)
+		}
+		undo = append(undo, r)
+	}
+	journal.Close()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for i := len(undo) - 1; i >= 0; i-- {
+		r := undo[i]
+		_, renamedErr := os.Lstat(r.from)
+		_, originalErr := os.Lstat(r.to)
+		if i == len(undo)-1 && os.IsNotExist(renamedErr) && originalErr == nil {
+			if !dryRun {
+				if err := truncateJournal(journalPath, undo[:i]); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if originalErr == nil {
+			return fmt.Errorf("Not restoring %s, %s exists", r.from, r.to)
+		}
+		if !dryRun {
+			if err := os.Rename(r.from, r.to); err != nil {
+				return err
+			}
+			//drop the entry right away so an interrupted undo can be resumed
+			if err := truncateJournal(journalPath, undo[:i]); err != nil {
+				return err
+			}
+		}
+		out("%s -> %s\n", r.from, r.to)
+	}
+	if dryRun {
+		return nil
+	}
+	return os.Remove(journalPath)
+}
+
+// journalEntry is a line of the journal holding both paths quoted, so that any character in file names is preserved
+func journalEntry(r rename) string {
+	return strconv.Quote(r.from) + "\t" + strconv.Quote(r.to) + "\n"
+}
+
+func parseJournalEntry(line string) (r rename, ok bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 2 {
+		return r, false
+	}
+	from, err := strconv.Unquote(fields[0])
+	if err != nil {
+		return r, false
+	}
+	to, err := strconv.Unquote(fields[1])
+	if err != nil {
+		return r, false
+	}
+	return rename{from, to}, true
+}
+
+func truncateJournal(journalPath string, remaining []rename) error {
+	var content strings.Builder
+	for _, r := range remaining {
+		content.WriteString(journalEntry(r))
+	}
+	return os.WriteFile(journalPath, []byte(content.String()), 0644)
+}
+
+func stamp(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("stamp", "FILE|GLOB...", errOut)
+	var opts stampOptions
+	flags.StringVar(&opts.template, "template", "{id}_{name}{ext}", "new file `name`, placeholders: {id} {value} {name} {ext}")
+	flags.StringVar(&opts.date, "date", "", "use the given `date` (20060102150405) instead of the modification times")
+	version2 := flags.Bool("2", false, "generate version 2 IDs")
+	dryRun := flags.Bool("dry-run", false, "only print what would be renamed")
+	journalPath := flags.String("journal", "ndocid-stamp.journal", "`file` recording the renames for undoing them")
+	undo := flags.Bool("undo", false, "revert all renames recorded in the journal")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	opts.version = ndocid.V1
+	if *version2 {
+		opts.version = ndocid.V2
+	}
+
+	if *undo {
+		if flags.NArg() != 0 {
+			errOut("No files expected when undoing (see ndocid stamp -h)")
+			return 2
+		}
+		if err := undoStamps(*journalPath, *dryRun, out); err != nil {
+			errOut("%s", err)
+			return 1
+		}
+		return 0
+	}
+	if flags.NArg() == 0 {
+		errOut("No files given (see ndocid stamp -h)")
+		return 2
+	}
+	files, err := expandPatterns(flags.Args())
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	journalAbs, _ := filepath.Abs(*journalPath)
+	for i, file := range files {
+		if abs, _ := filepath.Abs(file); abs == journalAbs {
+			files = append(files[:i], files[i+1:]...)
+			break
+		}
+	}
+	warn := func(format string, msg ...interface{}) {
+		errOut("Warning: "+format, msg...)
+	}
+	plan, err := planStamps(files, opts, warn)
+	if err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	if *dryRun {
+		for _, r := range plan {
+			out("%s -> %s\n", r.from, r.to)
+		}
+		return 0
+	}
+	if err := applyStamps(plan, *journalPath, out); err != nil {
+		errOut("%s", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+func listDir(t *testing.T, dir string) string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestStamp(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Unix(1552572000, 0)
+	for _, name := range []string{"b.pdf", "a.pdf", "c.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, mtime, mtime)
+	}
+	journal := filepath.Join(dir, "stamp.journal")
+	args := []string{"-journal", journal, filepath.Join(dir, "*.pdf"), filepath.Join(dir, "c.txt")}
+
+	var out string
+	if status := stamp(append([]string{"-dry-run"}, args...), spyIntoString(&out), silentOut); status != 0 {
+		t.Fatalf("dry run failed with status %d", status)
+	}
+	if listDir(t, dir) != "a.pdf b.pdf c.txt" || strings.Count(out, " -> ") != 3 {
+		t.Errorf("dry run should not rename but print, got %q", out)
+	}
+
+	//same second: ordered by path, the later ones get the following seconds
+	expected := []string{
+		ndocid.EncodeUint64(1552572000) + "_a.pdf",
+		ndocid.EncodeUint64(1552572001) + "_b.pdf",
+		ndocid.EncodeUint64(1552572002) + "_c.txt",
+		"stamp.journal",
+	}
+	sort.Strings(expected)
+	var warnings string
+	if status := stamp(args, silentOut, spyIntoString(&warnings)); status != 0 {
+		t.Fatalf("stamping failed with status %d", status)
+	}
+	if strings.Count(warnings, "Warning: ") != 2 || !strings.Contains(warnings, "c.txt gets the ID of 2 seconds later") {
+		t.Errorf("expected warnings about the shifted IDs but got %q", warnings)
+	}
+	if got := listDir(t, dir); got != strings.Join(expected, " ") {
+		t.Errorf("expected %v but got %s", expected, got)
+	}
+
+	if status := stamp([]string{"-undo", "-journal", journal}, silentOut, silentOut); status != 0 {
+		t.Fatalf("undo failed with status %d", status)
+	}
+	if got := listDir(t, dir); got != "a.pdf b.pdf c.txt" {
+		t.Errorf("expected original names but got %s", got)
+	}
+}
+
+func TestStampTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scan.pdf")
+	os.WriteFile(path, nil, 0644)
+	//dates are read in the local time zone
+	value, err := ndocid.ParseDatetime("20190314150000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dir, ndocid.EncodeUint64(value)+".pdf")
+	os.WriteFile(existing, nil, 0644)
+
+	plan, err := planStamps([]string{path}, stampOptions{"{id}{ext}", "20190314150000", ndocid.V1}, silentOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, ndocid.EncodeUint64(value+1)+".pdf"); plan[0].to != want {
+		t.Errorf("expected existing target to be skipped, got %s", plan[0].to)
+	}
+	plan, _ = planStamps([]string{path}, stampOptions{"{name}-{id}-{value}{ext}", "20190314150000", ndocid.V2}, silentOut)
+	if want := fmt.Sprintf("scan-%s-%d.pdf", ndocid.V2.Encode(value), value); filepath.Base(plan[0].to) != want {
+		t.Errorf("unexpected name %s", plan[0].to)
+	}
+	if _, err := planStamps([]string{path}, stampOptions{"{name}{ext}", "", ndocid.V1}, silentOut); err == nil {
+		t.Error("expected template without {id} to be rejected")
+	}
+	if _, err := planStamps([]string{path}, stampOptions{"x/{id}", "", ndocid.V1}, silentOut); err == nil {
+		t.Error("expected template with directory to be rejected")
+	}
+}
+
+func TestStampJournalFirst(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scan.pdf")
+	os.WriteFile(path, nil, 0644)
+	journal := filepath.Join(dir, "stamp.journal")
+
+	//the rename fails as the target directory is missing, its entry must not remain
+	missing := filepath.Join(dir, "missing", "x.pdf")
+	if err := applyStamps([]rename{{path, missing}}, journal, silentOut); err == nil {
+		t.Fatal("expected rename into missing directory to fail")
+	}
+	if content, _ := os.ReadFile(journal); len(content) != 0 {
+		t.Errorf("expected no journal entry for a failed rename but got %q", content)
+	}
+
+	//interrupted between writing the entry and renaming: undo drops the entry
+	target := filepath.Join(dir, "X_scan.pdf")
+	os.WriteFile(journal, []byte(journalEntry(rename{target, path})), 0644)
+	if err := undoStamps(journal, false, silentOut); err != nil {
+		t.Fatal(err)
+	}
+	if got := listDir(t, dir); got != "scan.pdf" {
+		t.Errorf("expected the original file only but got %s", got)
+	}
+}
+
+func TestStampJournalSpecialNames(t *testing.T) {
+	dir := t.TempDir()
+	name := "tab\tand\nnewline.pdf"
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Skip("file system does not support such names")
+	}
+	journal := filepath.Join(dir, "stamp.journal")
+	if status := stamp([]string{"-journal", journal, "-date", "20190314150000", path}, silentOut, silentOut); status != 0 {
+		t.Fatalf("stamping failed with status %d", status)
+	}
+	if status := stamp([]string{"-undo", "-journal", journal}, silentOut, silentOut); status != 0 {
+		t.Fatalf("undo failed with status %d", status)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected %q to be restored: %v", name, err)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+// watchEvent is logged as a single JSON line for every action
+type watchEvent struct {
+	Time   string `json:"time"`
+	Action string `json:"action"` //watching, detected, assigned, error or stopped
+	File   string `json:"file,omitempty"`
+	Target string `json:"target,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Value  uint64 `json:"value,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// arrival tracks a new file until its size and modification time stopped changing
+type arrival struct {
+	firstSeen   time.Time
+	size        int64
+	modTime     time.Time
+	stableSince time.Time
+}
+
+type watcher struct {
+	dir, outDir string
+	template    string
+	version     ndocid.Version
+	settle      time.Duration //how long a file must remain unchanged before it is processed
+	issuer      *issuer
+	log         func(watchEvent)
+	now         func() time.Time
+	pending     map[string]*arrival
+	failed      map[string]bool //files which could not be processed are left alone
+}
+
+func (w *watcher) event(e watchEvent) {
+	e.Time = w.now().Format(time.RFC3339)
+	w.log(e)
+}
+
+// poll checks the directory once and processes all files which have settled
+func (w *watcher) poll() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	now := w.now()
+	present := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || w.failed[name] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue //vanished in the meantime
+		}
+		present[name] = true
+		a, known := w.pending[name]
+		if !known {
+			w.pending[name] = &arrival{now, info.Size(), info.ModTime(), now}
+			w.event(watchEvent{Action: "detected", File: name})
+			continue
+		}
+		if info.Size() != a.size || !info.ModTime().Equal(a.modTime) {
+			a.size, a.modTime, a.stableSince = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(a.stableSince) < w.settle {
+			continue
+		}
+		delete(w.pending, name)
+		if err := w.assign(name, a.firstSeen); err != nil {
+			w.failed[name] = true
+			w.event(watchEvent{Action: "error", File: name, Error: err.Error()})
+		}
+	}
+	for name := range w.pending {
+		if !present[name] {
+			delete(w.pending, name)
+		}
+	}
+	return nil
+}
+
+// assign issues an ID for the arrival time and moves the file into the output folder
+func (w *watcher) assign(name string, arrived time.Time) error {
+	wanted := uint64(arrived.Unix())
+	for {
+		value, err := w.issuer.issue(wanted)
+		if err != nil {
+			return err
+		}
+		id := w.version.Encode(value)
+		target := filepath.Join(w.outDir, renderStampName(w.template, name, id, value))
+		if _, err := os.Lstat(target); err == nil {
+			wanted = value + 1
+			continue
+		}
+		if err := moveFile(filepath.Join(w.dir, name), target); err != nil {
+			return err
+		}
+		w.event(watchEvent{Action: "assigned", File: name, Target: target, ID: id, Value: value})
+		return nil
+	}
+}
+
+// moveFile renames the file, across file systems it is copied and removed instead
+func moveFile(from, to string) error {
+	err := os.Rename(from, to)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
+
+func watch(args []string, out outFunc, errOut outFunc) int {
+	flags := newFlagSet("watch", "DIR", errOut)
+	outDir := flags.String("out", "", "`folder` receiving the renamed files, required and different from the watched one")
+	template := flags.String("template", "{id}_{name}{ext}", "new file `name`, placeholders: {id} {value} {name} {ext}")
+	interval := flags.Duration("interval", 2*time.Second, "time between two polls of the folder")
+	settle := flags.Duration("settle", 5*time.Second, "time a file's size must remain unchanged before it is processed")
+	state := flags.String("state", defaultIssuerState(), "`file` remembering the last ID issued, shared with other commands issuing IDs")
+	version2 := flags.Bool("2", false, "generate version 2 IDs")
+	logPath := flags.String("log", "", "`file` to append the JSON lines log to instead of standard output")
+	if status, ok := parseFlags(flags, args); !ok {
+		return status
+	}
+	if flags.NArg() != 1 {
+		errOut("Exactly one folder to watch expected (see ndocid watch -h)")
+		return 2
+	}
+	if *outDir == "" {
+		errOut("Output folder required (see ndocid watch -h)")
+		return 2
+	}
+	if !strings.Contains(*template, "{id}") || strings.ContainsRune(*template, filepath.Separator) {
+		errOut("Template must contain {id} and no directories: %s", *template)
+		return 2
+	}
+
+	w := &watcher{
+		dir:      flags.Arg(0),
+		outDir:   *outDir,
+		template: *template,
+		version:  ndocid.V1,
+		settle:   *settle,
+		issuer:   newIssuer(*state),
+		now:      time.Now,
+		pending:  make(map[string]*arrival),
+		failed:   make(map[string]bool),
+	}
+	if *version2 {
+		w.version = ndocid.V2
+	}
+	for _, dir := range []string{w.dir, w.outDir} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			errOut("No folder: %s", dir)
+			return 1
+		}
+	}
+	absDir, _ := filepath.Abs(w.dir)
+	absOutDir, _ := filepath.Abs(w.outDir)
+	if absDir == absOutDir {
+		errOut("Output folder must differ from the watched one, renamed files would be picked up again")
+		return 2
+	}
+	logOut := io.Writer(os.Stdout)
+	if *logPath != "" {
+		file, err := os.OpenFile(*logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			errOut("%s", err)
+			return 1
+		}
+		defer file.Close()
+		logOut = file
+	}
+	encoder := json.NewEncoder(logOut)
+	w.log = func(e watchEvent) { encoder.Encode(e) }
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	w.event(watchEvent{Action: "watching", File: w.dir, Target: w.outDir})
+	for {
+		if err := w.poll(); err != nil {
+			w.event(watchEvent{Action: "error", File: w.dir, Error: err.Error()})
+		}
+		select {
+		case <-ctx.Done():
+			w.event(watchEvent{Action: "stopped", File: w.dir})
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/n2code/ndocid"
+)
+
+func TestWatcher(t *testing.T) {
+	inbox, done := t.TempDir(), t.TempDir()
+	clock := time.Unix(1552572000, 0)
+	var events []watchEvent
+	w := &watcher{
+		dir:      inbox,
+		outDir:   done,
+		template: "{id}_{name}{ext}",
+		version:  ndocid.V1,
+		settle:   5 * time.Second,
+		issuer:   newIssuer(filepath.Join(t.TempDir(), "issued")),
+		log:      func(e watchEvent) { events = append(events, e) },
+		now:      func() time.Time { return clock },
+		pending:  make(map[string]*arrival),
+		failed:   make(map[string]bool),
+	}
+	poll := func(advance time.Duration) {
+		t.Helper()
+		clock = clock.Add(advance)
+		if err := w.poll(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(inbox, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a.pdf", "scanning")
+	write("b.pdf", "done")
+	write(".partial", "ignored")
+	poll(0)
+	if len(events) != 2 || events[0].Action != "detected" {
+		t.Fatalf("expected two detections but got %+v", events)
+	}
+	write("a.pdf", "scanning more") //still growing
+	poll(3 * time.Second)
+	poll(3 * time.Second) //b.pdf settled
+	if _, err := os.Stat(filepath.Join(done, "72639D77LD_b.pdf")); err != nil {
+		t.Errorf("expected b.pdf to be moved with ID of its arrival: %+v", events)
+	}
+	poll(3 * time.Second) //a.pdf settled
+	//same arrival time but IDs are never reused
+	if _, err := os.Stat(filepath.Join(done, ndocid.EncodeUint64(1552572001)+"_a.pdf")); err != nil {
+		t.Errorf("expected a.pdf to get the next ID: %+v", events)
+	}
+	last := events[len(events)-1]
+	if last.Action != "assigned" || last.File != "a.pdf" || last.Value != 1552572001 {
+		t.Errorf("unexpected event %+v", last)
+	}
+	if _, err := os.Stat(filepath.Join(inbox, ".partial")); err != nil {
+		t.Error("hidden files should be left alone")
+	}
+}
@@ -0,0 +1,105 @@
+package ndocid
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	digitsFixedLength = 5  // leading digits shared with V1 IDs
+	digitsCheckLength = 2  // check digits following the leading digits
+	digitsMaxVariable = 16 // decimal digits of the 52 bits above the fixed part
+)
+
+// mod97 returns the remainder of the decimal number given by its digits modulo 97
+func mod97(digits string) int {
+	r := 0
+	for _, d := range digits {
+		r = (r*10 + int(d-'0')) % 97
+	}
+	return r
+}
+
+// EncodeDigits encodes the number using digits only, e.g. for telephone keypads. The first five digits equal those of the
+// V1 ID, followed by two check digits according to ISO 7064 MOD 97-10 over all other digits and the remaining bits in
+// decimal, least significant digit first. The check digits detect all single substitutions and adjacent transpositions.
+func EncodeDigits(x uint64) string {
+	var fixed []rune
+	for _, d := range fixedPart(x) {
+		fixed = append(fixed, customBase32Encode(d))
+	}
+	var variable strings.Builder
+	rest := x >> 12
+	for {
+		variable.WriteByte(byte('0' + rest%10))
+		rest /= 10
+		if rest == 0 {
+			break
+		}
+	}
+	check := 98 - mod97(string(fixed)+variable.String()+"00")
+	return fmt.Sprintf("%s%02d%s", string(fixed), check, variable.String())
+}
+
+// DecodeDigits returns the number encoded by EncodeDigits. Inputs of up to 7 digits are checked as far as possible,
+// complete is false for them unless they are invalid. Longer inputs must be valid complete IDs.
+func DecodeDigits(x string) (r uint64, err error, complete bool) {
+	for i, char := range x {
+		if char < '0' || char > '9' {
+			return 0, &DecodeError{i + 1, fmt.Sprintf("Non-numeric character in position %d: %c (%U)", i+1, char, char)}, false
+		}
+	}
+	fixed := x
+	if len(fixed) > digitsFixedLength {
+		fixed = fixed[:digitsFixedLength]
+	}
+	if _, err, _ := Decode(fixed); err != nil {
+		return 0, err, false
+	}
+	if len(x) <= digitsFixedLength+digitsCheckLength {
+		return 0, nil, false
+	}
+
+	variable := x[digitsFixedLength+digitsCheckLength:]
+	if len(variable) > digitsMaxVariable {
+		return 0, &DecodeError{len(x), "Too many digits for a 64 bit number"}, false
+	}
+	if mod97(fixed+variable+x[digitsFixedLength:digitsFixedLength+digitsCheckLength]) != 1 {
+		return 0, &DecodeError{digitsFixedLength + 1, fmt.Sprintf("ID invalid, check digits in position %d and %d do not match", digitsFixedLength+1, digitsFixedLength+2)}, false
+	}
+	var rest uint64
+	for i := len(variable) - 1; i >= 0; i-- {
+		rest = rest*10 + uint64(variable[i]-'0')
+	}
+	if rest >= 1<<52 {
+		return 0, &DecodeError{len(x), "Number exceeds 64 bits"}, false
+	}
+	for i, shift := 1, 0; i < digitsFixedLength; i, shift = i+1, shift+3 {
+		r |= uint64(fixed[i]-'2') << shift
+	}
+	return rest<<12 | r, nil, true
+}
+
+// DigitsFromID re-encodes a valid complete ID using digits only
+func DigitsFromID(id string) (string, error) {
+	x, err, complete := Decode(id)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		return "", fmt.Errorf("ID incomplete")
+	}
+	return EncodeDigits(x), nil
+}
+
+// IDFromDigits re-encodes valid complete digits as ID in the layout of the given version
+func IDFromDigits(digits string, v Version) (string, error) {
+	x, err, complete := DecodeDigits(digits)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		return "", fmt.Errorf("ID incomplete")
+	}
+	return v.Encode(x), nil
+}
@@ -0,0 +1,63 @@
+package ndocid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigits(t *testing.T) {
+	for _, x := range []uint64{0, 1, 4095, 4096, 1552572000, 1<<63 + 12345, ^uint64(0)} {
+		digits := EncodeDigits(x)
+		if strings.Trim(digits, "0123456789") != "" || digits[:5] != EncodeUint64(x)[:5] {
+			t.Errorf("%d: expected digits starting like the ID but got %s", x, digits)
+		}
+		decoded, err, complete := DecodeDigits(digits)
+		if err != nil || !complete || decoded != x {
+			t.Errorf("%d: %s decoded as %d (%v)", x, digits, decoded, err)
+		}
+	}
+	if digits := EncodeDigits(1552572000); digits != "7263956540973" {
+		t.Errorf("unexpected encoding %s", digits)
+	}
+	id, err := IDFromDigits("7263956540973", V2)
+	if err != nil || id != "72639Z77LD8" {
+		t.Errorf("expected conversion to 72639Z77LD8 but got %s (%v)", id, err)
+	}
+	if digits, err := DigitsFromID("72639D77LD"); err != nil || digits != "7263956540973" {
+		t.Errorf("expected conversion to digits but got %s (%v)", digits, err)
+	}
+
+	//every single substitution and adjacent transposition is detected
+	valid := EncodeDigits(1552572000)
+	for i := range valid {
+		for d := byte('0'); d <= '9'; d++ {
+			if d == valid[i] {
+				continue
+			}
+			typo := valid[:i] + string(d) + valid[i+1:]
+			if _, err, _ := DecodeDigits(typo); err == nil {
+				t.Errorf("substitution %s not detected", typo)
+			}
+		}
+		if i+1 < len(valid) && valid[i] != valid[i+1] {
+			typo := valid[:i] + string(valid[i+1]) + string(valid[i]) + valid[i+2:]
+			if _, err, _ := DecodeDigits(typo); err == nil {
+				t.Errorf("transposition %s not detected", typo)
+			}
+		}
+	}
+
+	partial := func(x string, expPartial bool) {
+		t.Helper()
+		_, err, complete := DecodeDigits(x)
+		if complete || (err == nil) != expPartial {
+			t.Errorf("%s: expected partial %t but got %v", x, expPartial, err)
+		}
+	}
+	partial("", true)
+	partial("726", true)
+	partial("7263902", true)
+	partial("92332", false)
+	partial("7263A", false)
+	partial("72639565409730000000000", false)
+}
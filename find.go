@@ -0,0 +1,91 @@
+package ndocid
+
+// Candidate is a word in a text which looks like an ID: five digits followed by digits and capital letters,
+// at least one of them a letter, 6 to 20 characters in total. Words are delimited by anything but ASCII letters and digits.
+// Words consisting of digits only or containing lower case letters are candidates only if they are valid complete IDs,
+// otherwise every number and many words would be reported, hence typos in such IDs go unnoticed.
+type Candidate struct {
+	Start, End int //byte offsets of the word within the text
+	Text       string
+	Value      uint64
+	Err        error
+	Complete   bool
+}
+
+// Valid reports whether the candidate is a complete ID passing all checks
+func (c Candidate) Valid() bool {
+	return c.Err == nil && c.Complete
+}
+
+// Canonical returns the text with aliases like S for 5 replaced by the characters of the alphabet
+func (c Candidate) Canonical() string {
+	return Canonical(c.Text)
+}
+
+// Match is a valid ID found in a text
+type Match struct {
+	Start, End int    //byte offsets of the ID within the text
+	Text       string //as written, possibly using aliases like S for 5
+	ID         string //canonical form
+	Value      uint64
+}
+
+func isWordByte(b byte) bool {
+	return '0' <= b && b <= '9' || 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z'
+}
+
+// idShape tells whether the word looks like an ID as described for Candidate or at least consists of
+// five digits followed by digits and letters of any case
+func idShape(word string) (likely bool, possible bool) {
+	if len(word) < 6 || len(word) > 20 {
+		return false, false
+	}
+	capitals, lower := 0, 0
+	for i := 0; i < len(word); i++ {
+		switch c := word[i]; {
+		case '0' <= c && c <= '9':
+		case i < 5:
+			return false, false
+		case 'A' <= c && c <= 'Z':
+			capitals++
+		case 'a' <= c && c <= 'z':
+			lower++
+		default:
+			return false, false
+		}
+	}
+	return capitals > 0 && lower == 0, true
+}
+
+// FindCandidates lists all words in the text which look like IDs together with their decoding results
+func FindCandidates(text string) (candidates []Candidate) {
+	for start := 0; start < len(text); {
+		if !isWordByte(text[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(text) && isWordByte(text[end]) {
+			end++
+		}
+		word := text[start:end]
+		if likely, possible := idShape(word); possible {
+			value, err, complete := Decode(word)
+			if likely || err == nil && complete {
+				candidates = append(candidates, Candidate{start, end, word, value, err, complete})
+			}
+		}
+		start = end
+	}
+	return
+}
+
+// FindAll lists all valid complete IDs in the text
+func FindAll(text string) (matches []Match) {
+	for _, c := range FindCandidates(text) {
+		if c.Valid() {
+			matches = append(matches, Match{c.Start, c.End, c.Text, c.Canonical(), c.Value})
+		}
+	}
+	return
+}
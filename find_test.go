@@ -0,0 +1,70 @@
+package ndocid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	text := "Ref. 72639D77LD, 72639Z77LD8 (v2), typo 52247CRMYT, date 20190314150000, again 52247CRMTY → 68495LTTOD/x 1234567ABC"
+	matches := FindAll(text)
+	expected := []string{"72639D77LD", "72639Z77LD8", "52247CRMTY", "68495LTTOD"}
+	if len(matches) != len(expected) {
+		t.Fatalf("expected %d matches but got %+v", len(expected), matches)
+	}
+	for i, m := range matches {
+		if m.ID != expected[i] || text[m.Start:m.End] != m.Text {
+			t.Errorf("expected %s but got %+v", expected[i], m)
+		}
+	}
+	if matches[0].Value != 1552572000 || matches[1].Value != 1552572000 {
+		t.Error("expected decoded values")
+	}
+
+	aliased := FindAll("see 22222317LD")
+	if len(aliased) != 1 || aliased[0].ID != "222223I7LD" || aliased[0].Text != "22222317LD" || aliased[0].Value != 1552609280 {
+		t.Errorf("expected canonical ID but got %+v", aliased)
+	}
+}
+
+func TestFindCanonical(t *testing.T) {
+	m := FindAll("(7263GD77LD)")
+	if len(m) != 0 {
+		t.Error("leading five characters must be digits")
+	}
+	m = FindAll("(52247CRMTY)")
+	if len(m) != 1 || m[0].Start != 1 || m[0].End != 11 {
+		t.Errorf("unexpected match %+v", m)
+	}
+}
+
+func TestFindCandidates(t *testing.T) {
+	candidates := FindCandidates("72639D77LD 52247CRMYT 72639Z 52247 1552572000 52247crmyt 72639D77LDABCDEFGHIJKL")
+	if len(candidates) != 3 {
+		t.Fatalf("expected 3 candidates but got %+v", candidates)
+	}
+	if !candidates[0].Valid() {
+		t.Error("expected valid candidate")
+	}
+	if candidates[1].Err == nil {
+		t.Error("expected invalid candidate")
+	}
+	if candidates[2].Err != nil || candidates[2].Complete {
+		t.Error("expected partial candidate")
+	}
+}
+
+func TestFindLowerCaseAndDigitsOnly(t *testing.T) {
+	//words of digits only or with lower case letters are only found if they are valid
+	text := "see 52247crmty, 942228 and 72639d77LD but not 52247crmyt, 942282 or 1552572000"
+	var found []string
+	for _, m := range FindAll(text) {
+		found = append(found, m.ID)
+	}
+	if strings.Join(found, " ") != "52247CRMTY 942228 72639D77LD" {
+		t.Errorf("unexpected matches %v", found)
+	}
+	if c := FindCandidates(text); len(c) != 3 {
+		t.Errorf("expected invalid lower case and digits only words to be skipped but got %+v", c)
+	}
+}
@@ -0,0 +1,56 @@
+package ndocid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minLength is the length of the shortest ID: the fixed part and the master check character
+const minLength = 6
+
+// EncodeFixed encodes the number as V1 ID of the given length. Shorter IDs are padded with 2s, i.e. zero chunks
+// of the variable part, which leave the master check unchanged. Values whose ID is longer fail.
+func EncodeFixed(x uint64, length int) (string, error) {
+	if err := CheckLength(length); err != nil {
+		return "", err
+	}
+	id := EncodeUint64(x)
+	if len(id) > length {
+		return "", fmt.Errorf("%d needs %d characters, more than %d", x, len(id), length)
+	}
+	padded := id + strings.Repeat(string(customBase32Encode(0)), length-len(id))
+	verboseLineOut("Padded to %d characters: %s", length, padded)
+	return padded, nil
+}
+
+// CheckLength fails unless V1 IDs can have the given length
+func CheckLength(length int) error {
+	if length < minLength || length > maxLength {
+		return fmt.Errorf("Length must be between %d and %d", minLength, maxLength)
+	}
+	return nil
+}
+
+// DecodeLength decodes the V1 ID like Decode but requires it to have the given length.
+// Shorter input is incomplete at best, longer input and V2 IDs are invalid.
+func DecodeLength(x string, length int) (r uint64, err error, complete bool) {
+	if err = CheckLength(length); err != nil {
+		return 0, err, false
+	}
+	r, err, complete = Decode(x)
+	if err != nil {
+		return
+	}
+	if runes := []rune(x); len(runes) >= 6 {
+		if d, _ := customBase32Decode(runes[5]); d == v2Marker {
+			return 0, &DecodeError{6, "Version 2 ID where version 1 is required"}, false
+		}
+	}
+	switch n := len([]rune(x)); {
+	case n > length:
+		return 0, &DecodeError{length + 1, fmt.Sprintf("ID longer than %d characters", length)}, false
+	case n < length:
+		return 0, nil, false
+	}
+	return
+}
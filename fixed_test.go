@@ -0,0 +1,53 @@
+package ndocid
+
+import (
+	"testing"
+)
+
+func TestEncodeFixed(t *testing.T) {
+	for _, x := range []uint64{0, 1552572000, 1 << 40, ^uint64(0)} {
+		for length := len(EncodeUint64(x)); length <= maxLength; length++ {
+			id, err := EncodeFixed(x, length)
+			if err != nil || len(id) != length {
+				t.Errorf("%d: expected %d characters but got %s (%v)", x, length, id, err)
+				continue
+			}
+			decoded, err, complete := DecodeLength(id, length)
+			if err != nil || !complete || decoded != x {
+				t.Errorf("%s: decoded %d (%v, %v)", id, decoded, err, complete)
+			}
+		}
+	}
+	if id, err := EncodeFixed(1552572000, 12); err != nil || id != "72639D77LD22" {
+		t.Errorf("expected 72639D77LD22 but got %s (%v)", id, err)
+	}
+	if _, err := EncodeFixed(1552572000, 9); err == nil {
+		t.Error("expected value too large for the length to fail")
+	}
+	for _, length := range []int{0, 5, 18} {
+		if _, err := EncodeFixed(0, length); err == nil {
+			t.Errorf("expected length %d to be rejected", length)
+		}
+	}
+}
+
+func TestDecodeLength(t *testing.T) {
+	if _, err, complete := DecodeLength("72639D77LD", 12); err != nil || complete {
+		t.Errorf("expected shorter ID to be partial but got %v, %v", err, complete)
+	}
+	_, err, _ := DecodeLength("72639D77LD22", 10)
+	if e, ok := err.(*DecodeError); !ok || e.Position != 11 {
+		t.Errorf("expected error at 11 but got %v", err)
+	}
+	if _, err, _ := DecodeLength("72639D77DL22", 12); err == nil {
+		t.Error("expected invalid ID to be rejected")
+	}
+	if _, err, _ := DecodeLength(V2.Encode(1552572000), 11); err == nil {
+		t.Error("expected V2 ID to be rejected")
+	}
+	for _, length := range []int{-2, 5, 18} {
+		if _, err, _ := DecodeLength("72639D77LD", length); err == nil {
+			t.Errorf("expected length %d to be rejected", length)
+		}
+	}
+}
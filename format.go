@@ -0,0 +1,21 @@
+package ndocid
+
+import (
+	"strings"
+)
+
+// GroupLength is the number of characters per group when formatting IDs for humans
+const GroupLength = 5
+
+// Group makes an ID easier to read by inserting sep after the five leading digits and after every further five characters.
+// Decode does not accept the separators, they have to be removed first.
+func Group(id string, sep string) string {
+	var grouped strings.Builder
+	for i, r := range []rune(id) {
+		if i > 0 && i%GroupLength == 0 {
+			grouped.WriteString(sep)
+		}
+		grouped.WriteRune(r)
+	}
+	return grouped.String()
+}
@@ -0,0 +1,19 @@
+package ndocid
+
+import (
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	assert := func(id, sep, exp string) {
+		t.Helper()
+		if got := Group(id, sep); got != exp {
+			t.Errorf("expected %q but got %q", exp, got)
+		}
+	}
+	assert("72639D77LD", " ", "72639 D77LD")
+	assert("72639Z77LD8", " ", "72639 Z77LD 8")
+	assert("49999ZZZZZWZZZZFZZ5P", "-", "49999-ZZZZZ-WZZZZ-FZZ5P")
+	assert("72639", " ", "72639")
+	assert("", " ", "")
+}
@@ -14,6 +14,9 @@ const dateFormat = "20060102150405"
 
 const customBase32Alphabet = string("23456789ABCDEFHIJKLMNOPQRTUVWXYZ")
 
+// Alphabet lists the characters of generated IDs ordered by the value they represent
+const Alphabet = customBase32Alphabet
+
 // Verbose causes all encoding operations to write the algorithm steps to os.Stdout if set
 var Verbose bool
 var verboseLineOut = func(format string, msg ...interface{}) {
@@ -74,8 +77,17 @@ func EncodeUint64(i uint64) string {
 }
 
 func EncodeBitstring(s string) (result string, err error) {
+	number, err := ParseBitstring(s)
+	if err != nil {
+		return
+	}
+	result = encode(number)
+	return
+}
+
+// ParseBitstring converts the bitstring input accepted by EncodeBitstring to the number it represents
+func ParseBitstring(s string) (number uint64, err error) {
 	verboseLineOut("Received bitstring input: %s", s)
-	var number uint64
 	if s == "" {
 		err = fmt.Errorf("Empty bitstring input")
 		return
@@ -101,11 +113,20 @@ func EncodeBitstring(s string) (result string, err error) {
 			return
 		}
 	}
-	result = encode(number)
 	return
 }
 
 func EncodeDatetime(s string) (result string, err error) {
+	unixSeconds, err := ParseDatetime(s)
+	if err != nil {
+		return
+	}
+	result = EncodeUint64(unixSeconds)
+	return
+}
+
+// ParseDatetime converts the date input accepted by EncodeDatetime to unix time in seconds
+func ParseDatetime(s string) (unixSeconds uint64, err error) {
 	if len(s) != len(dateFormat) {
 		err = fmt.Errorf("Input date does not match required %d-character-format (see -h)", len(dateFormat))
 		return
@@ -115,9 +136,8 @@ func EncodeDatetime(s string) (result string, err error) {
 		err = fmt.Errorf("Bad date format: %s", err)
 		return
 	}
-	unixSeconds := t.Unix()
-	verboseLineOut("Received date input: %s (unix time in seconds: %d)", t.Format(time.RFC1123Z), unixSeconds)
-	result = EncodeUint64(uint64(unixSeconds))
+	verboseLineOut("Received date input: %s (unix time in seconds: %d)", t.Format(time.RFC1123Z), t.Unix())
+	unixSeconds = uint64(t.Unix())
 	return
 }
 
@@ -125,15 +145,9 @@ func encode(x uint64) (r string) {
 	var acc strings.Builder
 
 	//Algorithm
-	f1 := int(x & 0b000000000111 >> 0)
-	f2 := int(x & 0b000000111000 >> 3)
-	f3 := int(x & 0b000111000000 >> 6)
-	f4 := int(x & 0b111000000000 >> 9)
-	p2 := bits.OnesCount64(x&0b000000111111) & 0b1
-	p3 := bits.OnesCount64(x&0b000111111111) & 0b1
-	p4 := bits.OnesCount64(x&0b111111111111) & 0b1
-	fc := p2<<2 + p3<<1 + p4
-	fp := [...]int{fc, f1, f2, f3, f4}
+	fp := fixedPart(x)
+	fc, f1, f2, f3, f4 := fp[0], fp[1], fp[2], fp[3], fp[4]
+	p2, p3, p4 := fc>>2&0b1, fc>>1&0b1, fc&0b1
 	fs := 3*fc + 1*f1 + 3*f2 + 1*f3 + 3*f4
 
 	vp := make([]int, 0, 11)
@@ -194,6 +208,29 @@ func encode(x uint64) (r string) {
 	return r
 }
 
+// DecodeError is returned by Decode for rejected IDs, Position is the 1-based character position at which the ID was found to be invalid
+type DecodeError struct {
+	Position int
+	msg      string
+}
+
+func (e *DecodeError) Error() string {
+	return e.msg
+}
+
+// fixedPart calculates the leading check character FC and the characters F1 to F4 holding the 12 LSB
+func fixedPart(x uint64) [5]int {
+	f1 := int(x & 0b000000000111 >> 0)
+	f2 := int(x & 0b000000111000 >> 3)
+	f3 := int(x & 0b000111000000 >> 6)
+	f4 := int(x & 0b111000000000 >> 9)
+	p2 := bits.OnesCount64(x&0b000000111111) & 0b1
+	p3 := bits.OnesCount64(x&0b000111111111) & 0b1
+	p4 := bits.OnesCount64(x&0b111111111111) & 0b1
+	fc := p2<<2 + p3<<1 + p4
+	return [...]int{fc, f1, f2, f3, f4}
+}
+
 func Decode(x string) (r uint64, err error, complete bool) {
 	defer func() {
 		if err != nil {
@@ -213,11 +250,11 @@ func Decode(x string) (r uint64, err error, complete bool) {
 		pos++
 		d, mapped := customBase32Decode(char)
 		if !mapped {
-			err = fmt.Errorf("Bad character in position %d: %c (%U)", pos, char, char)
+			err = &DecodeError{pos, fmt.Sprintf("Bad character in position %d: %c (%U)", pos, char, char)}
 			return
 		}
 		if pos <= 5 && d > 7 {
-			err = fmt.Errorf("Non-[2,9]-numeric character in position %d: %c (%U)", pos, char, char)
+			err = &DecodeError{pos, fmt.Sprintf("Non-[2,9]-numeric character in position %d: %c (%U)", pos, char, char)}
 			return
 		}
 		id = append(id, uint64(d))
@@ -230,25 +267,30 @@ func Decode(x string) (r uint64, err error, complete bool) {
 	if pos >= 3 {
 		r |= id[2] << 3
 		if (bits.OnesCount64(r)+int((id[0]&0b100)>>2))%2 == 1 {
-			err = fmt.Errorf("ID invalid starting at position 3")
+			err = &DecodeError{3, "ID invalid starting at position 3"}
 			return
 		}
 	}
 	if pos >= 4 {
 		r |= id[3] << 6
 		if (bits.OnesCount64(r)+int((id[0]&0b010)>>1))%2 == 1 {
-			err = fmt.Errorf("ID invalid starting at position 4")
+			err = &DecodeError{4, "ID invalid starting at position 4"}
 			return
 		}
 	}
 	if pos >= 5 {
 		r |= id[4] << 9
 		if (bits.OnesCount64(r)+int((id[0]&0b001)>>0))%2 == 1 {
-			err = fmt.Errorf("ID invalid starting at position 5")
+			err = &DecodeError{5, "ID invalid starting at position 5"}
 			return
 		}
 	}
 
+	if pos >= 6 && id[5] == v2Marker {
+		r, err, complete = decodeV2(id, r)
+		return
+	}
+
 	for i := 6; i < len(id); i++ {
 		r |= uint64(id[i] << (12 + (i-6)*5))
 	}
@@ -258,7 +300,7 @@ func Decode(x string) (r uint64, err error, complete bool) {
 	}
 
 	if check%29 != 0 {
-		err = fmt.Errorf("ID invalid starting after position 5")
+		err = &DecodeError{6, "ID invalid starting after position 5"}
 		return
 	}
 
@@ -0,0 +1,151 @@
+// Package ndocidhttp provides net/http middleware which validates IDs taken from requests.
+//
+// Requests with a missing, invalid or partial ID are answered with 400 Bad Request and an
+// RFC 7807 problem+JSON body, valid ones reach the wrapped handler with the decoded value in their context.
+package ndocidhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/n2code/ndocid"
+)
+
+// Extractor takes the raw ID from a request, ok is false if the request contains none
+type Extractor func(r *http.Request) (id string, ok bool)
+
+// FromPathSegment extracts the segment of the URL path at the given index, negative indexes count from the end.
+// For "/documents/72639D77LD/pdf" index 1 and -2 both yield "72639D77LD".
+func FromPathSegment(index int) Extractor {
+	return func(r *http.Request) (string, bool) {
+		segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		i := index
+		if i < 0 {
+			i += len(segments)
+		}
+		if i < 0 || i >= len(segments) || segments[i] == "" {
+			return "", false
+		}
+		return segments[i], true
+	}
+}
+
+// FromQuery extracts the query parameter of the given name
+func FromQuery(name string) Extractor {
+	return func(r *http.Request) (string, bool) {
+		id := r.URL.Query().Get(name)
+		return id, id != ""
+	}
+}
+
+// Problem is the problem+JSON body sent for rejected IDs. Its type is about:blank, the extension member code
+// tells the kind of problem.
+type Problem struct {
+	Type        string       `json:"type"`
+	Code        string       `json:"code"`
+	Title       string       `json:"title"`
+	Status      int          `json:"status"`
+	Detail      string       `json:"detail"`
+	ID          string       `json:"id,omitempty"`
+	Position    int          `json:"position,omitempty"` //1-based position at which the ID was found to be invalid
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+}
+
+// Suggestion is a valid ID a single typo away from the rejected one
+type Suggestion struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`
+	Position int    `json:"position"`
+	Likely   bool   `json:"likely"`
+}
+
+// ProblemType is the type of all problems, RFC 7807 defines no further semantics for it
+const ProblemType = "about:blank"
+
+// Problem codes
+const (
+	ProblemMissingID = "missing-id"
+	ProblemInvalidID = "invalid-id"
+	ProblemPartialID = "partial-id"
+)
+
+// Check validates the ID given by extract and returns the problem if it is rejected
+func Check(r *http.Request, extract Extractor) (value uint64, problem *Problem) {
+	id, ok := extract(r)
+	if !ok {
+		return 0, &Problem{Type: ProblemType, Code: ProblemMissingID, Title: "Missing ID", Status: http.StatusBadRequest, Detail: "The request contains no ID"}
+	}
+	//overlong input is rejected before it makes decoding and suggesting expensive
+	if n := utf8.RuneCountInString(id); n > ndocid.MaxIDLength {
+		detail := fmt.Sprintf("ID of %d characters exceeds the maximum of %d", n, ndocid.MaxIDLength)
+		return 0, &Problem{Type: ProblemType, Code: ProblemInvalidID, Title: "Invalid ID", Status: http.StatusBadRequest, Detail: detail, Position: ndocid.MaxIDLength + 1}
+	}
+	value, err, complete := ndocid.Decode(id)
+	switch {
+	case err != nil:
+		problem = &Problem{Type: ProblemType, Code: ProblemInvalidID, Title: "Invalid ID", Status: http.StatusBadRequest, Detail: err.Error(), ID: id}
+		var decodeErr *ndocid.DecodeError
+		if errors.As(err, &decodeErr) {
+			problem.Position = decodeErr.Position
+		}
+		for _, c := range ndocid.Suggest(id) {
+			problem.Suggestions = append(problem.Suggestions, Suggestion{c.ID, c.Kind.String(), c.Position, c.Likely})
+		}
+		return 0, problem
+	case !complete:
+		return 0, &Problem{Type: ProblemType, Code: ProblemPartialID, Title: "Incomplete ID", Status: http.StatusBadRequest, Detail: "ID incomplete, needs further characters", ID: id}
+	}
+	return value, nil
+}
+
+// WriteProblem answers the request with the problem
+func WriteProblem(w http.ResponseWriter, problem *Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the decoded ID value
+func NewContext(ctx context.Context, value uint64) context.Context {
+	return context.WithValue(ctx, contextKey{}, value)
+}
+
+// FromContext returns the decoded ID value stored by the middleware, ok is false if there is none
+func FromContext(ctx context.Context) (value uint64, ok bool) {
+	value, ok = ctx.Value(contextKey{}).(uint64)
+	return
+}
+
+// Middleware validates the ID given by extract before passing the request on to the next handler
+func Middleware(extract Extractor) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value, problem := Check(r, extract)
+			if problem != nil {
+				WriteProblem(w, problem)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), value)))
+		})
+	}
+}
+
+// HandlerFunc is a handler receiving the decoded ID value directly
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, value uint64)
+
+// Handle wraps f so it is only called for requests with a valid ID, useful for registering routes:
+//
+//	mux.Handle("/documents/", ndocidhttp.Handle(ndocidhttp.FromPathSegment(1), showDocument))
+func Handle(extract Extractor, f HandlerFunc) http.Handler {
+	return Middleware(extract)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, _ := FromContext(r.Context())
+		f(w, r, value)
+	}))
+}
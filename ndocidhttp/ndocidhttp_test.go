@@ -0,0 +1,99 @@
+package ndocidhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractors(t *testing.T) {
+	assert := func(extract Extractor, target string, expID string, expOK bool) {
+		t.Helper()
+		id, ok := extract(httptest.NewRequest(http.MethodGet, target, nil))
+		if id != expID || ok != expOK {
+			t.Errorf("%s: expected %q/%t but got %q/%t", target, expID, expOK, id, ok)
+		}
+	}
+	assert(FromPathSegment(1), "/documents/72639D77LD/pdf", "72639D77LD", true)
+	assert(FromPathSegment(-2), "/documents/72639D77LD/pdf", "72639D77LD", true)
+	assert(FromPathSegment(-1), "/documents/72639D77LD/", "", false)
+	assert(FromPathSegment(3), "/documents/72639D77LD/pdf", "", false)
+	assert(FromPathSegment(-4), "/documents/72639D77LD/pdf", "", false)
+	assert(FromPathSegment(0), "/", "", false)
+	last := FromPathSegment(-1)
+	assert(last, "/a/b/c", "c", true)
+	assert(last, "/a", "a", true) //reusable for paths of other lengths
+	assert(FromQuery("id"), "/documents?id=72639D77LD", "72639D77LD", true)
+	assert(FromQuery("id"), "/documents?id=", "", false)
+}
+
+func serve(target string) (*httptest.ResponseRecorder, string) {
+	var handled string
+	handler := Handle(FromPathSegment(-1), func(w http.ResponseWriter, r *http.Request, value uint64) {
+		handled = fmt.Sprint(value)
+	})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder, handled
+}
+
+func TestValidID(t *testing.T) {
+	recorder, handled := serve("/documents/72639D77LD")
+	if recorder.Code != http.StatusOK || handled != "1552572000" {
+		t.Errorf("expected value 1552572000 to be handled but got status %d and %q", recorder.Code, handled)
+	}
+	recorder, handled = serve("/documents/72639Z77LD8")
+	if handled != "1552572000" {
+		t.Errorf("expected version 2 ID to be accepted but got status %d", recorder.Code)
+	}
+}
+
+func TestRejectedIDs(t *testing.T) {
+	assert := func(target string, expCode string, expPosition int, expSuggestion string) {
+		t.Helper()
+		recorder, handled := serve(target)
+		if handled != "" {
+			t.Errorf("%s: handler should not have been called", target)
+		}
+		if recorder.Code != http.StatusBadRequest || recorder.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("%s: expected problem+JSON 400 but got %d", target, recorder.Code)
+		}
+		var problem Problem
+		if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Type != ProblemType || problem.Code != expCode || problem.Status != http.StatusBadRequest || problem.Position != expPosition {
+			t.Errorf("%s: unexpected problem %+v", target, problem)
+		}
+		found := expSuggestion == ""
+		for _, s := range problem.Suggestions {
+			found = found || s.ID == expSuggestion
+		}
+		if !found {
+			t.Errorf("%s: expected suggestion %s", target, expSuggestion)
+		}
+	}
+	assert("/documents/", ProblemMissingID, 0, "")
+	assert("/documents/72639D77DL", ProblemInvalidID, 6, "72639D77LD")
+	assert("/documents/72X39D77LD", ProblemInvalidID, 3, "")
+	assert("/documents/72639", ProblemPartialID, 0, "")
+	assert("/documents/"+strings.Repeat("9", 1000), ProblemInvalidID, 21, "")
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+		t.Error("expected no value")
+	}
+	var value uint64
+	var ok bool
+	handler := Middleware(FromQuery("id"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, ok = FromContext(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?id=72639D77LD", nil))
+	if !ok || value != 1552572000 {
+		t.Errorf("expected value 1552572000 in context but got %d", value)
+	}
+}
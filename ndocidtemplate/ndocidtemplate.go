@@ -0,0 +1,131 @@
+// Package ndocidtemplate provides template functions generating and presenting IDs in documents.
+//
+//	{{ ndocid .Number }}            ID of an integer (also as float, json.Number or decimal string) or time.Time
+//	{{ ndocidDate .ID }}            time.Time of an ID, e.g. {{ (ndocidDate .ID).Format "2006-01-02" }}
+//	{{ ndocidFormat " " .ID }}      ID grouped for humans, e.g. 72639 D77LD
+//	{{ ndocidDecode .ID }}          integer of an ID
+//	{{ ndocidBarcode .ID }}         Code 128 barcode of an ID as SVG
+//
+// Invalid input makes the execution of the template fail.
+package ndocidtemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"reflect"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/n2code/ndocid"
+	"github.com/n2code/ndocid/barcode"
+)
+
+// BarcodeScale is the size of a barcode module in pixels
+const BarcodeScale = 2
+
+// Encode generates the version 1 ID of a non-negative integer, given as any integer type, as integral float
+// like numbers from encoding/json, as json.Number or as decimal string, or of a point in time
+func Encode(x interface{}) (string, error) {
+	if t, ok := x.(time.Time); ok {
+		if t.Unix() < 0 {
+			return "", fmt.Errorf("Cannot generate ID from negative %v", x)
+		}
+		return ndocid.V1.Encode(uint64(t.Unix())), nil
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ndocid.V1.Encode(v.Uint()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return "", fmt.Errorf("Cannot generate ID from negative %v", x)
+		}
+		return ndocid.V1.Encode(uint64(v.Int())), nil
+	case reflect.Float32, reflect.Float64:
+		return encodeFloat(v.Float())
+	case reflect.String:
+		if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return ndocid.V1.Encode(n), nil
+		}
+		if _, isNumber := x.(json.Number); isNumber {
+			if f, err := strconv.ParseFloat(v.String(), 64); err == nil {
+				return encodeFloat(f)
+			}
+		}
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return "", fmt.Errorf("Cannot generate ID from negative %v", x)
+		}
+		return "", fmt.Errorf("Cannot generate ID from %q, no decimal integer", v.String())
+	}
+	return "", fmt.Errorf("Cannot generate ID from %T", x)
+}
+
+// encodeFloat generates the version 1 ID of an integral float
+func encodeFloat(f float64) (string, error) {
+	switch {
+	case f < 0:
+		return "", fmt.Errorf("Cannot generate ID from negative %v", f)
+	case f != math.Trunc(f) || f >= 1<<64:
+		return "", fmt.Errorf("Cannot generate ID from %v, no integer of 64 bits", f)
+	}
+	return ndocid.V1.Encode(uint64(f)), nil
+}
+
+// Decode returns the integer of a complete valid ID
+func Decode(id string) (uint64, error) {
+	value, err, complete := ndocid.Decode(id)
+	if err == nil && !complete {
+		err = fmt.Errorf("ID incomplete: %s", id)
+	}
+	return value, err
+}
+
+// Date returns the point in time of a complete valid ID in the local time zone
+func Date(id string) (time.Time, error) {
+	value, err := Decode(id)
+	return time.Unix(int64(value), 0), err
+}
+
+// Format groups a complete valid ID for humans, see ndocid.Group
+func Format(sep string, id string) (string, error) {
+	if _, err := Decode(id); err != nil {
+		return "", err
+	}
+	return ndocid.Group(id, sep), nil
+}
+
+// Barcode renders a complete valid ID as Code 128 barcode in SVG
+func Barcode(id string) (string, error) {
+	if _, err := Decode(id); err != nil {
+		return "", err
+	}
+	symbol, err := barcode.Code128(id)
+	if err != nil {
+		return "", err
+	}
+	return symbol.SVG(BarcodeScale), nil
+}
+
+// FuncMap holds the functions for text/template
+var FuncMap = template.FuncMap{
+	"ndocid":        Encode,
+	"ndocidDate":    Date,
+	"ndocidFormat":  Format,
+	"ndocidDecode":  Decode,
+	"ndocidBarcode": Barcode,
+}
+
+// HTMLFuncMap holds the functions for html/template where the barcode is embedded unescaped
+var HTMLFuncMap = htmltemplate.FuncMap{
+	"ndocid":       Encode,
+	"ndocidDate":   Date,
+	"ndocidFormat": Format,
+	"ndocidDecode": Decode,
+	"ndocidBarcode": func(id string) (htmltemplate.HTML, error) {
+		svg, err := Barcode(id)
+		return htmltemplate.HTML(svg), err
+	},
+}
@@ -0,0 +1,64 @@
+package ndocidtemplate
+
+import (
+	"encoding/json"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func execute(t *testing.T, text string, data interface{}) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(FuncMap).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	err = tmpl.Execute(&out, data)
+	return out.String(), err
+}
+
+func TestFuncMap(t *testing.T) {
+	assert := func(text string, data interface{}, exp string) {
+		t.Helper()
+		got, err := execute(t, text, data)
+		if err != nil || got != exp {
+			t.Errorf("%s: expected %q but got %q (%v)", text, exp, got, err)
+		}
+	}
+	assert(`{{ ndocid . }}`, uint64(1552572000), "72639D77LD")
+	assert(`{{ ndocid . }}`, 1552572000, "72639D77LD")
+	assert(`{{ ndocid . }}`, time.Unix(1552572000, 0), "72639D77LD")
+	for _, number := range []interface{}{int8(7), int16(7), uint8(7), uint16(7), float32(7), 7.0, json.Number("7"), json.Number("7e0"), "7"} {
+		assert(`{{ ndocid . }}`, number, "992223")
+	}
+	assert(`{{ ndocid . }}`, 1552572000.0, "72639D77LD")
+	assert(`{{ ndocid . }}`, "1552572000", "72639D77LD")
+	assert(`{{ ndocidDecode . }}`, "72639D77LD", "1552572000")
+	assert(`{{ (ndocidDate .).Unix }}`, "72639Z77LD8", "1552572000")
+	assert(`{{ . | ndocidFormat " " }}`, "72639D77LD", "72639 D77LD")
+
+	for _, bad := range []string{`{{ ndocid -1 }}`, `{{ ndocid "x" }}`, `{{ ndocid "-1" }}`, `{{ ndocid 1.5 }}`, `{{ ndocid -1.0 }}`, `{{ ndocid 1e20 }}`, `{{ ndocid true }}`, `{{ ndocidDecode "72639D77DL" }}`, `{{ ndocidDecode "72639" }}`, `{{ ndocidBarcode "x" }}`} {
+		if _, err := execute(t, bad, nil); err == nil {
+			t.Errorf("%s: expected failure", bad)
+		}
+	}
+}
+
+func TestBarcode(t *testing.T) {
+	svg, err := execute(t, `{{ ndocidBarcode . }}`, "72639D77LD")
+	if err != nil || !strings.HasPrefix(svg, "<svg") {
+		t.Errorf("expected SVG but got %q (%v)", svg, err)
+	}
+
+	tmpl := htmltemplate.Must(htmltemplate.New("test").Funcs(HTMLFuncMap).Parse(`<p>{{ ndocidBarcode . }}</p>`))
+	var out strings.Builder
+	if err := tmpl.Execute(&out, "72639D77LD"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "<p><svg") {
+		t.Errorf("expected unescaped SVG but got %q", out.String())
+	}
+}
@@ -0,0 +1,162 @@
+package ndocid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChildKind tells what a child of a document is, its letter introduces the child in a reference
+type ChildKind rune
+
+const (
+	Revision   ChildKind = 'R'
+	Attachment ChildKind = 'A'
+)
+
+// RefSeparator separates the ID of the document and its children in a reference
+const RefSeparator = "-"
+
+func (k ChildKind) String() string {
+	switch k {
+	case Revision:
+		return "revision"
+	case Attachment:
+		return "attachment"
+	}
+	return fmt.Sprintf("ChildKind(%q)", rune(k))
+}
+
+// ParseChildKind returns the kind named by its letter or its name
+func ParseChildKind(s string) (ChildKind, error) {
+	for _, k := range []ChildKind{Revision, Attachment} {
+		if strings.EqualFold(s, k.String()) || strings.EqualFold(s, string(rune(k))) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown kind of child: %s", s)
+}
+
+// Child is a step from a document to one of its revisions or attachments, numbered from 1
+type Child struct {
+	Kind  ChildKind
+	Index int
+}
+
+// Ref references a document by its ID, or one of its revisions or attachments, which may have children themselves.
+// Written out every child follows the separator as kind letter, decimal index and a check character,
+// e.g. 96822L9IPD-R2C-A1V for the first attachment of the second revision of 96822L9IPD.
+// The check character is calculated over all preceding characters using the Damm algorithm like in V2 IDs.
+type Ref struct {
+	ID       string
+	Children []Child
+}
+
+// Child returns the reference to a child of the referenced document
+func (r Ref) Child(kind ChildKind, index int) Ref {
+	children := append(make([]Child, 0, len(r.Children)+1), r.Children...)
+	return Ref{r.ID, append(children, Child{kind, index})}
+}
+
+// Parent returns the reference to the document the referenced child belongs to, ok is false for a plain ID
+func (r Ref) Parent() (parent Ref, ok bool) {
+	if len(r.Children) == 0 {
+		return r, false
+	}
+	return Ref{r.ID, r.Children[:len(r.Children)-1]}, true
+}
+
+// Ancestors returns the references from the plain ID down to the parent of the referenced document
+func (r Ref) Ancestors() (ancestors []Ref) {
+	for i := range r.Children {
+		ancestors = append(ancestors, Ref{r.ID, r.Children[:i]})
+	}
+	return
+}
+
+// Next returns the reference to the next child of the given kind: the next sibling if the referenced document
+// is a child of that kind itself, e.g. the next revision of a revision, or else its first child of that kind
+func (r Ref) Next(kind ChildKind) Ref {
+	if parent, ok := r.Parent(); ok {
+		if last := r.Children[len(r.Children)-1]; last.Kind == kind {
+			return parent.Child(kind, last.Index+1)
+		}
+	}
+	return r.Child(kind, 1)
+}
+
+func (r Ref) String() string {
+	var acc strings.Builder
+	acc.WriteString(r.ID)
+	interim := refInterim(0, r.ID)
+	for _, c := range r.Children {
+		step := string(rune(c.Kind)) + strconv.Itoa(c.Index)
+		interim = refInterim(interim, step)
+		check := dammCheck(interim)
+		interim = dammStep(interim, check)
+		acc.WriteString(RefSeparator + step)
+		acc.WriteRune(customBase32Encode(check))
+	}
+	return acc.String()
+}
+
+// refInterim continues the Damm algorithm with the characters, skipping separators
+func refInterim(interim int, s string) int {
+	for _, char := range s {
+		if d, ok := customBase32Decode(char); ok {
+			interim = dammStep(interim, d)
+		}
+	}
+	return interim
+}
+
+// ParseRef checks the ID and the check characters of all children and returns the reference in canonical form
+func ParseRef(s string) (r Ref, err error) {
+	parts := strings.Split(s, RefSeparator)
+	var complete bool
+	if IsSortable(parts[0]) {
+		_, err, complete = DecodeSortable(parts[0])
+	} else {
+		_, err, complete = Decode(parts[0])
+	}
+	r.ID = strings.Map(canonicalRune, parts[0])
+	if err != nil {
+		return Ref{}, err
+	}
+	if !complete {
+		return Ref{}, fmt.Errorf("ID incomplete: %s", parts[0])
+	}
+
+	pos := len([]rune(parts[0])) + 1
+	for _, part := range parts[1:] {
+		child, err := parseChild(r.String(), part, pos+1)
+		if err != nil {
+			return Ref{}, err
+		}
+		r.Children = append(r.Children, child)
+		pos += len(part) + 1
+	}
+	return r, nil
+}
+
+// parseChild reads a single child written after the canonical reference to its parent, starting at position pos
+func parseChild(parent string, part string, pos int) (c Child, err error) {
+	if len(part) < 3 {
+		return c, &DecodeError{pos, fmt.Sprintf("Child too short in position %d: %q", pos, part)}
+	}
+	kind, err := ParseChildKind(part[:1])
+	if err != nil {
+		return c, &DecodeError{pos, fmt.Sprintf("Unknown kind of child in position %d: %s", pos, part[:1])}
+	}
+	digits := part[1 : len(part)-1]
+	index, err := strconv.Atoi(digits)
+	if err != nil || index < 1 || digits[0] == '0' || strings.Trim(digits, "0123456789") != "" {
+		return c, &DecodeError{pos + 1, fmt.Sprintf("Bad index of child in position %d: %s", pos+1, digits)}
+	}
+	c = Child{kind, index}
+	expected := []rune(Ref{parent, []Child{c}}.String())
+	if check, ok := customBase32Decode(rune(part[len(part)-1])); !ok || customBase32Encode(check) != expected[len(expected)-1] {
+		return c, &DecodeError{pos, fmt.Sprintf("Reference invalid starting at position %d", pos)}
+	}
+	return c, nil
+}
@@ -0,0 +1,86 @@
+package ndocid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRef(t *testing.T) {
+	doc := Ref{ID: "96822L9IPD"}
+	attachment := doc.Child(Revision, 2).Child(Attachment, 1)
+	if s := attachment.String(); s != "96822L9IPD-R2C-A1V" {
+		t.Errorf("expected 96822L9IPD-R2C-A1V but got %s", s)
+	}
+	if doc.String() != "96822L9IPD" || len(doc.Children) != 0 {
+		t.Error("building children changed the parent")
+	}
+
+	parent, ok := attachment.Parent()
+	if !ok || parent.String() != "96822L9IPD-R2C" {
+		t.Errorf("unexpected parent %s", parent)
+	}
+	if _, ok := doc.Parent(); ok {
+		t.Error("plain ID has no parent")
+	}
+	var ancestors []string
+	for _, a := range attachment.Ancestors() {
+		ancestors = append(ancestors, a.String())
+	}
+	if !reflect.DeepEqual(ancestors, []string{"96822L9IPD", "96822L9IPD-R2C"}) {
+		t.Errorf("unexpected ancestors %v", ancestors)
+	}
+
+	next := func(r Ref, kind ChildKind, expected string) {
+		t.Helper()
+		if n := r.Next(kind); n.String() != expected {
+			t.Errorf("%s: expected next %s %s but got %s", r, kind, expected, n)
+		}
+	}
+	next(doc, Revision, "96822L9IPD-R1N")
+	next(parent, Revision, "96822L9IPD-R3A")
+	next(parent, Attachment, "96822L9IPD-R2C-A1V")
+	next(attachment, Attachment, "96822L9IPD-R2C-A27")
+}
+
+func TestParseRef(t *testing.T) {
+	for _, s := range []string{"96822L9IPD", "96822L9IPD-R2C-A1V", "96822L9IPD-R12F", "72639Z77LD8-R1K", "Z2222223HANQM2V-A1V"} {
+		r, err := ParseRef(s)
+		if err != nil || r.String() != s {
+			t.Errorf("%s: parsed as %s (%v)", s, r, err)
+		}
+	}
+	r, err := ParseRef("96822l9ipd-r2c-a1v")
+	if err != nil || !reflect.DeepEqual(r, Ref{"96822L9IPD", []Child{{Revision, 2}, {Attachment, 1}}}) {
+		t.Errorf("lower case: parsed as %#v (%v)", r, err)
+	}
+
+	bad := func(s string, pos int) {
+		t.Helper()
+		_, err := ParseRef(s)
+		if e, ok := err.(*DecodeError); !ok || e.Position != pos {
+			t.Errorf("%q: expected error at %d but got %v", s, pos, err)
+		}
+	}
+	bad("96822L9IDP-R2C", 6)
+	bad("96822L9IPD-R3C", 12)
+	bad("96822L9IPD-A2C", 12)
+	bad("96822L9IPD-R2C-A2V", 16)
+	bad("96822L9IPD-R22C", 12)
+	bad("96822L9IPD-X2C", 12)
+	bad("96822L9IPD-R02C", 13)
+	bad("96822L9IPD-R", 12)
+	bad("96822L9IPD-", 12)
+	if _, err := ParseRef("96822-R1N"); err == nil {
+		t.Error("expected incomplete ID to be rejected")
+	}
+
+	if k, err := ParseChildKind("Attachment"); err != nil || k != Attachment {
+		t.Errorf("expected attachment but got %v (%v)", k, err)
+	}
+	if k, err := ParseChildKind("r"); err != nil || k != Revision {
+		t.Errorf("expected revision but got %v (%v)", k, err)
+	}
+	if _, err := ParseChildKind("draft"); err == nil {
+		t.Error("expected unknown kind to be rejected")
+	}
+}
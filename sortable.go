@@ -0,0 +1,98 @@
+package ndocid
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	sortableMarker   = 31 // Z, IDs of the other layouts start with a digit
+	sortableDataLen  = 13 // characters of 5 bits holding 64 bits, the first one at most 15
+	sortableLength   = 1 + sortableDataLen + 1
+	sortableMaxFirst = 1<<(64-5*(sortableDataLen-1)) - 1
+)
+
+// EncodeSortable encodes the number in the sortable layout: the marker Z followed by the number in 13 characters,
+// most significant bits first, and a check character according to the Damm algorithm over GF(32) like in V2 IDs.
+// All sortable IDs have the same length and because the alphabet is in ASCII order they sort like their numbers.
+func EncodeSortable(x uint64) string {
+	syms := make([]int, 0, sortableLength)
+	syms = append(syms, sortableMarker)
+	for i := sortableDataLen - 1; i >= 0; i-- {
+		syms = append(syms, int(x>>(5*i)&0b11111))
+	}
+	interim := 0
+	for _, d := range syms {
+		interim = dammStep(interim, d)
+	}
+	syms = append(syms, dammCheck(interim))
+
+	var acc strings.Builder
+	for _, d := range syms {
+		acc.WriteRune(customBase32Encode(d))
+	}
+	return acc.String()
+}
+
+// IsSortable reports whether the ID starts with the marker of the sortable layout
+func IsSortable(id string) bool {
+	for _, char := range id {
+		d, ok := customBase32Decode(char)
+		return ok && d == sortableMarker
+	}
+	return false
+}
+
+// DecodeSortable returns the number encoded by EncodeSortable. Plausible beginnings of sortable IDs
+// are reported as incomplete like Decode does for the other layouts.
+func DecodeSortable(x string) (r uint64, err error, complete bool) {
+	interim, pos := 0, 0
+	for _, char := range x {
+		pos++
+		d, mapped := customBase32Decode(char)
+		switch {
+		case !mapped:
+			return 0, &DecodeError{pos, fmt.Sprintf("Bad character in position %d: %c (%U)", pos, char, char)}, false
+		case pos == 1 && d != sortableMarker:
+			return 0, &DecodeError{pos, fmt.Sprintf("Sortable ID expected to start with %c", customBase32Encode(sortableMarker))}, false
+		case pos == 2 && d > sortableMaxFirst:
+			return 0, &DecodeError{pos, "Number exceeds 64 bits"}, false
+		case pos > sortableLength:
+			return 0, &DecodeError{pos, fmt.Sprintf("Sortable ID longer than %d characters", sortableLength)}, false
+		case pos > 1 && pos < sortableLength:
+			r = r<<5 | uint64(d)
+		}
+		interim = dammStep(interim, d)
+	}
+	if pos < sortableLength {
+		return 0, nil, false
+	}
+	if interim != 0 {
+		return 0, &DecodeError{2, "ID invalid starting at position 2"}, false
+	}
+	return r, nil, true
+}
+
+// SortableFromID re-encodes a valid complete ID in the sortable layout
+func SortableFromID(id string) (string, error) {
+	x, err, complete := Decode(id)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		return "", fmt.Errorf("ID incomplete")
+	}
+	return EncodeSortable(x), nil
+}
+
+// IDFromSortable re-encodes a valid complete sortable ID in the layout of the given version
+func IDFromSortable(sortable string, v Version) (string, error) {
+	x, err, complete := DecodeSortable(sortable)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		return "", fmt.Errorf("ID incomplete")
+	}
+	return v.Encode(x), nil
+}
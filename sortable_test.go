@@ -0,0 +1,114 @@
+package ndocid
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSortable(t *testing.T) {
+	for _, x := range []uint64{0, 1, 31, 32, 1552572000, 1<<63 + 12345, ^uint64(0)} {
+		s := EncodeSortable(x)
+		decoded, err, complete := DecodeSortable(s)
+		if err != nil || !complete || decoded != x {
+			t.Errorf("%d: %s decoded as %d (%v, %v)", x, s, decoded, err, complete)
+		}
+		if len(s) != sortableLength {
+			t.Errorf("%d: %s is not %d characters long", x, s, sortableLength)
+		}
+		if _, err, _ := Decode(s); err == nil {
+			t.Errorf("%s: expected Decode to reject the sortable layout", s)
+		}
+	}
+	if s := EncodeSortable(1552572000); s != "Z2222223HANQM2V" {
+		t.Errorf("expected Z2222223HANQM2V but got %s", s)
+	}
+	if !IsSortable("z2222223hanqm2v") || IsSortable("72639D77LD") || IsSortable("") {
+		t.Error("marker not recognized")
+	}
+	if x, err, complete := DecodeSortable("z2222223hanqm2v"); err != nil || !complete || x != 1552572000 {
+		t.Errorf("lower case: decoded %d (%v, %v)", x, err, complete)
+	}
+
+	values := []uint64{0, ^uint64(0)}
+	for i := 0; i < 1000; i++ {
+		values = append(values, rand.Uint64()>>uint(rand.Intn(64)))
+	}
+	ids := make([]string, len(values))
+	for i, x := range values {
+		ids[i] = EncodeSortable(x)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	sort.Strings(ids)
+	for i, id := range ids {
+		if x, _, _ := DecodeSortable(id); x != values[i] {
+			t.Fatalf("string order differs from numeric order at %s", id)
+		}
+	}
+}
+
+func TestSortableErrors(t *testing.T) {
+	partial := func(s string) {
+		t.Helper()
+		if _, err, complete := DecodeSortable(s); err != nil || complete {
+			t.Errorf("%q: expected partial but got %v, %v", s, err, complete)
+		}
+	}
+	bad := func(s string, pos int) {
+		t.Helper()
+		_, err, _ := DecodeSortable(s)
+		if e, ok := err.(*DecodeError); !ok || e.Position != pos {
+			t.Errorf("%q: expected error at %d but got %v", s, pos, err)
+		}
+	}
+	partial("")
+	partial("Z")
+	partial("Z2222223HANQM2")
+	bad("72639D77LD", 1)
+	bad("ZZ", 2)
+	bad("Z2222223HANQM2V2", 16)
+	bad("Z2222223HANQM2_", 15)
+	bad("Z2222223HANQM22", 2)
+
+	//every single substitution and adjacent transposition is detected
+	id := []rune(EncodeSortable(1552572000))
+	for i := 1; i < len(id); i++ {
+		for _, r := range customBase32Alphabet {
+			if r == id[i] {
+				continue
+			}
+			changed := append([]rune{}, id...)
+			changed[i] = r
+			if _, err, _ := DecodeSortable(string(changed)); err == nil {
+				t.Errorf("substitution %s not detected", string(changed))
+			}
+		}
+		if i+1 < len(id) && id[i] != id[i+1] {
+			changed := append([]rune{}, id...)
+			changed[i], changed[i+1] = changed[i+1], changed[i]
+			if _, err, _ := DecodeSortable(string(changed)); err == nil {
+				t.Errorf("transposition %s not detected", string(changed))
+			}
+		}
+	}
+}
+
+func TestSortableConversion(t *testing.T) {
+	s, err := SortableFromID("72639D77LD")
+	if err != nil || s != "Z2222223HANQM2V" {
+		t.Errorf("expected Z2222223HANQM2V but got %s (%v)", s, err)
+	}
+	for _, v := range []Version{V1, V2} {
+		id, err := IDFromSortable(s, v)
+		if err != nil || id != v.Encode(1552572000) {
+			t.Errorf("%s: expected %s but got %s (%v)", v, v.Encode(1552572000), id, err)
+		}
+	}
+	if _, err := SortableFromID("72639"); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected incomplete ID to be rejected but got %v", err)
+	}
+	if _, err := IDFromSortable("Z2222223HANQM2", V1); err == nil {
+		t.Error("expected incomplete sortable ID to be rejected")
+	}
+}
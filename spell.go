@@ -0,0 +1,166 @@
+package ndocid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SpellingAlphabet maps the characters of IDs to words which are hard to mishear when dictated
+type SpellingAlphabet struct {
+	Name string
+	// Words holds the spoken word of every digit and letter
+	Words map[rune]string
+	// Aliases holds further words understood when parsing, e.g. outdated or regional variants
+	Aliases map[string]rune
+
+	lookupOnce sync.Once
+	lookup     map[string]rune
+}
+
+// SpellingAlphabets holds the alphabets available by name, further ones can be added
+var SpellingAlphabets = map[string]*SpellingAlphabet{
+	NATO.Name:    NATO,
+	DIN5009.Name: DIN5009,
+}
+
+// NATO is the international radiotelephony spelling alphabet with English digits
+var NATO = &SpellingAlphabet{
+	Name: "nato",
+	Words: map[rune]string{
+		'0': "zero", '1': "one", '2': "two", '3': "three", '4': "four",
+		'5': "five", '6': "six", '7': "seven", '8': "eight", '9': "nine",
+		'A': "alfa", 'B': "bravo", 'C': "charlie", 'D': "delta", 'E': "echo", 'F': "foxtrot", 'G': "golf",
+		'H': "hotel", 'I': "india", 'J': "juliett", 'K': "kilo", 'L': "lima", 'M': "mike", 'N': "november",
+		'O': "oscar", 'P': "papa", 'Q': "quebec", 'R': "romeo", 'S': "sierra", 'T': "tango", 'U': "uniform",
+		'V': "victor", 'W': "whiskey", 'X': "x-ray", 'Y': "yankee", 'Z': "zulu",
+	},
+	Aliases: map[string]rune{
+		"tree": '3', "fower": '4', "fife": '5', "niner": '9',
+		"alpha": 'A', "juliet": 'J', "whisky": 'W',
+	},
+}
+
+// DIN5009 is the German spelling alphabet of DIN 5009:2022 naming cities, the traditional names are understood as well
+var DIN5009 = &SpellingAlphabet{
+	Name: "din5009",
+	Words: map[rune]string{
+		'0': "null", '1': "eins", '2': "zwei", '3': "drei", '4': "vier",
+		'5': "fünf", '6': "sechs", '7': "sieben", '8': "acht", '9': "neun",
+		'A': "Aachen", 'B': "Berlin", 'C': "Chemnitz", 'D': "Düsseldorf", 'E': "Essen", 'F': "Frankfurt", 'G': "Goslar",
+		'H': "Hamburg", 'I': "Ingelheim", 'J': "Jena", 'K': "Köln", 'L': "Leipzig", 'M': "München", 'N': "Nürnberg",
+		'O': "Offenbach", 'P': "Potsdam", 'Q': "Quickborn", 'R': "Rostock", 'S': "Salzwedel", 'T': "Tübingen", 'U': "Unna",
+		'V': "Völklingen", 'W': "Wuppertal", 'X': "Xanten", 'Y': "Ypsilon", 'Z': "Zwickau",
+	},
+	Aliases: map[string]rune{
+		"zwo":   '2',
+		"anton": 'A', "berta": 'B', "cäsar": 'C', "dora": 'D', "emil": 'E', "friedrich": 'F', "gustav": 'G',
+		"heinrich": 'H', "ida": 'I', "julius": 'J', "kaufmann": 'K', "ludwig": 'L', "martha": 'M', "nordpol": 'N',
+		"otto": 'O', "paula": 'P', "quelle": 'Q', "richard": 'R', "samuel": 'S', "siegfried": 'S', "theodor": 'T',
+		"ulrich": 'U', "viktor": 'V', "wilhelm": 'W', "xanthippe": 'X', "zacharias": 'Z', "zeppelin": 'Z',
+	},
+}
+
+// normalizeWord folds case, umlauts and hyphens so that transcriptions like "Duesseldorf" or "xray" are understood
+func normalizeWord(word string) string {
+	return strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss", "-", "", "'", "").Replace(strings.ToLower(word))
+}
+
+func (a *SpellingAlphabet) word(normalized string) (r rune, ok bool) {
+	a.lookupOnce.Do(func() {
+		lookup := make(map[string]rune)
+		add := func(word string, r rune) {
+			lookup[normalizeWord(word)] = r
+			//transcriptions often drop the umlaut dots
+			lookup[normalizeWord(strings.NewReplacer("ä", "a", "ö", "o", "ü", "u").Replace(strings.ToLower(word)))] = r
+		}
+		for r, word := range a.Words {
+			add(word, r)
+		}
+		for word, r := range a.Aliases {
+			add(word, r)
+		}
+		a.lookup = lookup
+	})
+	r, ok = a.lookup[normalized]
+	return
+}
+
+// Spell returns the words of the characters of the ID separated by spaces
+func (a *SpellingAlphabet) Spell(id string) (string, error) {
+	var words []string
+	for i, r := range []rune(strings.ToUpper(id)) {
+		word, ok := a.Words[r]
+		if !ok {
+			return "", fmt.Errorf("No word for %q at position %d in spelling alphabet %s", r, i+1, a.Name)
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// Transcribe turns spoken words back into the characters of an ID without checking it.
+// Single characters and digits may be given as such, words are separated by spaces or punctuation.
+func (a *SpellingAlphabet) Transcribe(spoken string) (id string, err error) {
+	var transcribed strings.Builder
+	words := strings.FieldsFunc(spoken, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '.' || r == ';' || r == '/'
+	})
+	for i, word := range words {
+		if r, ok := a.word(normalizeWord(word)); ok {
+			transcribed.WriteRune(r)
+			continue
+		}
+		literal := strings.ToUpper(word)
+		if len(word) == 1 && unicode.IsLetter(rune(literal[0])) || strings.Trim(word, "0123456789") == "" {
+			transcribed.WriteString(literal)
+			continue
+		}
+		return "", fmt.Errorf("Unknown word %q at position %d in spelling alphabet %s", word, i+1, a.Name)
+	}
+	if transcribed.Len() == 0 {
+		return "", fmt.Errorf("Nothing spoken")
+	}
+	return transcribed.String(), nil
+}
+
+// Parse transcribes the spoken words and checks the resulting ID, returning its canonical form
+func (a *SpellingAlphabet) Parse(spoken string) (id string, value uint64, err error) {
+	id, err = a.Transcribe(spoken)
+	if err != nil {
+		return "", 0, err
+	}
+	value, err, complete := Decode(id)
+	if err == nil && !complete {
+		err = fmt.Errorf("ID incomplete: %s", id)
+	}
+	if err != nil {
+		return id, 0, err
+	}
+	return VersionOf(id).Encode(value), value, nil
+}
+
+// TranscribeAny transcribes the spoken words using the first of the SpellingAlphabets, ordered by name, understanding all of them
+func TranscribeAny(spoken string) (id string, a *SpellingAlphabet, err error) {
+	var names []string
+	for name := range SpellingAlphabets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var firstErr error
+	for _, name := range names {
+		a = SpellingAlphabets[name]
+		if id, err = a.Transcribe(spoken); err == nil {
+			return id, a, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = fmt.Errorf("No spelling alphabet available")
+	}
+	return "", nil, firstErr
+}
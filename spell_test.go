@@ -0,0 +1,63 @@
+package ndocid
+
+import (
+	"testing"
+)
+
+func TestSpell(t *testing.T) {
+	assert := func(a *SpellingAlphabet, id string, exp string) {
+		t.Helper()
+		spelled, err := a.Spell(id)
+		if err != nil || spelled != exp {
+			t.Errorf("%s: expected %q but got %q (%v)", id, exp, spelled, err)
+		}
+	}
+	assert(NATO, "96822L9IPD", "nine six eight two two lima nine india papa delta")
+	assert(NATO, "72639z77ld8", "seven two six three nine zulu seven seven lima delta eight")
+	assert(DIN5009, "72639D77LD", "sieben zwei sechs drei neun Düsseldorf sieben sieben Leipzig Düsseldorf")
+	if _, err := NATO.Spell("72639-D77LD"); err == nil {
+		t.Error("expected unknown characters to be rejected")
+	}
+
+	//every character of IDs has a word in every alphabet
+	for _, a := range SpellingAlphabets {
+		if _, err := a.Spell(Alphabet); err != nil {
+			t.Errorf("%s: %s", a.Name, err)
+		}
+	}
+}
+
+func TestParseSpoken(t *testing.T) {
+	assert := func(a *SpellingAlphabet, spoken string, exp string) {
+		t.Helper()
+		id, value, err := a.Parse(spoken)
+		if err != nil || id != exp {
+			t.Errorf("%q: expected %s but got %s (%v)", spoken, exp, id, err)
+		}
+		if v, _, _ := Decode(exp); v != value {
+			t.Errorf("%q: expected value %d but got %d", spoken, v, value)
+		}
+	}
+	assert(NATO, "nine six eight two two lima nine india papa delta", "96822L9IPD")
+	assert(NATO, "Niner, Six, Eight, Two, Two. Lima Niner India Papa Delta", "96822L9IPD")
+	assert(NATO, "96822 lima 9 I papa D", "96822L9IPD")
+	assert(NATO, "seven two six three nine zulu seven seven lima delta eight", "72639Z77LD8")
+	assert(DIN5009, "sieben zwo sechs drei neun Duesseldorf sieben sieben Leipzig Dora", "72639D77LD")
+	assert(DIN5009, "sieben zwei sechs drei neun dusseldorf sieben sieben ludwig DÜSSELDORF", "72639D77LD")
+	//sierra is understood as 5 like S in typed IDs
+	assert(NATO, "six eight four nine sierra lima tango tango oscar delta", "68495LTTOD")
+
+	for _, bad := range []string{"", "nine six eight two two lima nine india papa", "nine six eight two two lima nine india delta papa", "nine six eight two two lima nine india papa dog"} {
+		if _, _, err := NATO.Parse(bad); err == nil {
+			t.Errorf("%q: expected failure", bad)
+		}
+	}
+
+	id, a, err := TranscribeAny("sieben zwei sechs drei neun Dora sieben sieben Leipzig Düsseldorf")
+	if err != nil || id != "72639D77LD" || a != DIN5009 {
+		t.Errorf("expected transcription using %s but got %s (%v)", DIN5009.Name, id, err)
+	}
+	if _, _, err := TranscribeAny("seven zwei"); err == nil {
+		t.Error("expected mixed alphabets to be rejected")
+	}
+}
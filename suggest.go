@@ -0,0 +1,124 @@
+package ndocid
+
+import (
+	"sort"
+	"strings"
+)
+
+// EditKind classifies the single edit that a Correction applies
+type EditKind int
+
+const (
+	Transposition EditKind = iota
+	Substitution
+	Deletion
+	Insertion
+)
+
+func (k EditKind) String() string {
+	switch k {
+	case Transposition:
+		return "transposition"
+	case Substitution:
+		return "substitution"
+	case Deletion:
+		return "deletion"
+	case Insertion:
+		return "insertion"
+	}
+	return "unknown edit"
+}
+
+// Correction is a single edit which turns an invalid ID into a valid complete one
+type Correction struct {
+	ID       string
+	Kind     EditKind
+	Position int  // 1-based position of the edited character, for transpositions the first of both
+	Likely   bool // set if the edit swaps neighbours or replaces a character by a look-alike
+}
+
+// pairs of characters which look alike, aliases like S for 5 are handled by decoding instead
+var confusables = []string{"8B", "2Z", "DO", "OQ", "DQ", "UV", "VY", "MN", "MW", "EF", "CE", "PR", "7T", "4A", "IJ", "KX"}
+
+// Confusable reports whether two different characters are easily mistaken for each other in written form
+func Confusable(a, b rune) bool {
+	for _, pair := range confusables {
+		if strings.ContainsRune(pair, a) && strings.ContainsRune(pair, b) && a != b {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalRune maps aliases like S (for 5) to the character of the custom Base32 alphabet
+func canonicalRune(r rune) rune {
+	if d, ok := customBase32Decode(r); ok {
+		return customBase32Encode(d)
+	}
+	return r
+}
+
+// Canonical returns the ID in upper case with aliases like S for 5 replaced by the characters of the alphabet
+func Canonical(id string) string {
+	return strings.Map(canonicalRune, id)
+}
+
+// Suggest lists all valid complete IDs which are a single edit away from the given invalid ID.
+// The likely corrections come first. Valid or plausible partial input yields no suggestions,
+// neither does input too long to become an ID by a single edit.
+func Suggest(x string) (suggestions []Correction) {
+	if _, err, _ := Decode(x); err == nil {
+		return nil
+	}
+
+	in := []rune(strings.ToUpper(x))
+	if len(in) > MaxIDLength+1 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	try := func(candidate []rune, kind EditKind, pos int, likely bool) {
+		id := string(candidate)
+		if seen[id] {
+			return
+		}
+		if _, err, complete := Decode(id); err == nil && complete {
+			seen[id] = true
+			suggestions = append(suggestions, Correction{id, kind, pos, likely})
+		}
+	}
+	// splice replaces the characters in [from,to) by the given ones
+	splice := func(from, to int, with ...rune) []rune {
+		c := make([]rune, 0, len(in)+1)
+		c = append(c, in[:from]...)
+		c = append(c, with...)
+		return append(c, in[to:]...)
+	}
+
+	for i := 0; i+1 < len(in); i++ {
+		if canonicalRune(in[i]) == canonicalRune(in[i+1]) {
+			continue
+		}
+		try(splice(i, i+2, in[i+1], in[i]), Transposition, i+1, true)
+	}
+	for i := range in {
+		for _, r := range customBase32Alphabet {
+			if r == canonicalRune(in[i]) {
+				continue
+			}
+			try(splice(i, i+1, r), Substitution, i+1, Confusable(canonicalRune(in[i]), r))
+		}
+	}
+	for i := range in {
+		try(splice(i, i+1), Deletion, i+1, false)
+	}
+	for i := 0; i <= len(in); i++ {
+		for _, r := range customBase32Alphabet {
+			try(splice(i, i, r), Insertion, i+1, false)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(a, b int) bool {
+		return suggestions[a].Likely && !suggestions[b].Likely
+	})
+	return
+}
@@ -0,0 +1,56 @@
+package ndocid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	assertSuggested := func(input string, exp string, kind EditKind, pos int, likely bool) {
+		for _, c := range Suggest(input) {
+			if c.ID == exp {
+				if c.Kind != kind || c.Position != pos || c.Likely != likely {
+					t.Errorf(`"%s" suggested for "%s" as %s at %d (likely: %t) but expected %s at %d (likely: %t)`, exp, input, c.Kind, c.Position, c.Likely, kind, pos, likely)
+				}
+				return
+			}
+		}
+		t.Errorf(`"%s" not suggested for "%s"`, exp, input)
+	}
+	assertNoSuggestions := func(input string) {
+		if s := Suggest(input); len(s) != 0 {
+			t.Errorf(`unexpected suggestions for "%s": %v`, input, s)
+		}
+	}
+
+	//original: 94875JBZOD
+	assertSuggested("94875KBZOD", "94875JBZOD", Substitution, 6, false)
+	assertSuggested("94785JBZOD", "94875JBZOD", Transposition, 3, true)
+	assertSuggested("94875J8ZOD", "94875JBZOD", Substitution, 7, true)
+	assertSuggested("94875KBZOD", "94875KBZDD", Substitution, 9, true)
+	assertSuggested("9487JBZOD", "94875JBZOD", Insertion, 5, false)
+	assertSuggested("948755JBZOD", "94875JBZOD", Deletion, 5, false)
+	assertSuggested("2222!X", "22222X", Substitution, 5, false)
+
+	for _, c := range Suggest("94875KBZOD") {
+		if _, err, complete := Decode(c.ID); err != nil || !complete {
+			t.Errorf(`suggestion "%s" is not a valid ID`, c.ID)
+		}
+	}
+	s := Suggest("94785JBZOD")
+	if !s[0].Likely || s[len(s)-1].Likely {
+		t.Errorf("likely suggestions not listed first: %v", s)
+	}
+
+	assertNoSuggestions("94875JBZOD")
+	assertNoSuggestions("9487")
+	assertNoSuggestions("")
+
+	//the longest IDs are still found, longer input is not tried
+	longest := V2.Encode(^uint64(0))
+	if len(longest) != maxV2Length {
+		t.Errorf("expected longest ID of %d characters but got %s", maxV2Length, longest)
+	}
+	assertSuggested(longest[:8]+"2"+longest[8:], longest, Deletion, 9, false)
+	assertNoSuggestions(strings.Repeat("94875JBZOD", 100))
+}
@@ -0,0 +1,194 @@
+package ndocid
+
+import (
+	"math/bits"
+)
+
+// Trace is a step-by-step account of decoding an ID as produced by DecodeTrace
+type Trace struct {
+	Input    string
+	Version  Version
+	Chars    []TracedChar
+	Parity   []ParityCheck
+	Master   MasterCheck // version 1 only
+	Damm     []DammCheck // version 2 only
+	Value    uint64
+	Err      error
+	Complete bool
+	// Corrections lists single edits resulting in a valid ID, see Suggest
+	Corrections []Correction
+	// Suspects are the 1-based positions of the characters which are most likely wrong
+	Suspects []int
+}
+
+// TracedChar describes how a single input character was mapped
+type TracedChar struct {
+	Position int
+	Char     rune
+	Value    int // -1 if the character is not part of the alphabet
+	Weight   int // factor of the value in the master check sum
+}
+
+// ParityCheck describes one of the parity bits stored in the leading check character FC
+type ParityCheck struct {
+	Name      string // P2, P3 or P4
+	Position  int    // position of the last character covered, the check happens once it is read
+	Covers    []int  // positions of the characters whose bits are covered
+	Bits      int    // number of least significant bits covered
+	Expected  int    // parity bit as stored in FC
+	Actual    int    // parity of the covered bits
+	Evaluated bool   // false if the input ended or failed before the check
+}
+
+// OK reports whether the check was evaluated and passed
+func (p ParityCheck) OK() bool {
+	return p.Evaluated && p.Expected == p.Actual
+}
+
+// MasterCheck describes the weighted sum over all characters which has to be a multiple of 29
+type MasterCheck struct {
+	Sum       int
+	Remainder int  // Sum modulo 29, i.e. how far off the sum is
+	Evaluated bool // false for input shorter than 6 characters or if a previous check failed
+}
+
+// OK reports whether the check was evaluated and passed
+func (m MasterCheck) OK() bool {
+	return m.Evaluated && m.Remainder == 0
+}
+
+// DammCheck describes a check character of a V2 ID
+type DammCheck struct {
+	Position int // position of the check character
+	Interim  int // interim digit of the Damm algorithm after the check character, has to be 0
+	Final    bool
+}
+
+// OK reports whether the check passed
+func (d DammCheck) OK() bool {
+	return d.Interim == 0
+}
+
+// DecodeTrace decodes the given ID like Decode does but records every intermediate step
+func DecodeTrace(x string) (t Trace) {
+	t.Input = x
+	t.Version = VersionOf(x)
+	t.Value, t.Err, t.Complete = Decode(x)
+
+	failedAt := 0
+	if decodeErr, ok := t.Err.(*DecodeError); ok {
+		failedAt = decodeErr.Position
+	}
+
+	var fixed [5]int
+	pos, digits, mapped := 0, 0, 0
+	for _, char := range x {
+		pos++
+		tc := TracedChar{Position: pos, Char: char, Value: -1, Weight: 1 + pos%2*2}
+		if d, ok := customBase32Decode(char); ok {
+			tc.Value = d
+			t.Master.Sum += tc.Weight * tc.Value
+			mapped++
+			if pos <= 5 && d <= 7 && digits == pos-1 {
+				fixed[pos-1] = d
+				digits++
+			}
+		}
+		t.Chars = append(t.Chars, tc)
+	}
+
+	low := uint64(fixed[1]) | uint64(fixed[2])<<3 | uint64(fixed[3])<<6 | uint64(fixed[4])<<9
+	parityOK := true
+	for i, name := range []string{"P2", "P3", "P4"} {
+		p := ParityCheck{Name: name, Position: i + 3, Bits: (i + 2) * 3}
+		for c := 2; c <= p.Position; c++ {
+			p.Covers = append(p.Covers, c)
+		}
+		p.Expected = fixed[0] >> (2 - i) & 0b1
+		if parityOK && digits >= p.Position && mapped >= p.Position {
+			p.Actual = bits.OnesCount64(low&(1<<p.Bits-1)) & 0b1
+			p.Evaluated = true
+			parityOK = p.OK()
+		}
+		t.Parity = append(t.Parity, p)
+	}
+
+	if pos >= 6 && parityOK && digits == 5 && mapped == pos {
+		if t.Version == V2 {
+			t.Damm = traceDamm(t.Chars)
+		} else {
+			t.Master.Remainder = t.Master.Sum % 29
+			t.Master.Evaluated = true
+		}
+	}
+
+	if t.Err == nil {
+		return
+	}
+	t.Corrections = Suggest(x)
+	if failedAt < 6 || t.Chars[failedAt-1].Value < 0 {
+		t.Suspects = prefixSuspects([]rune(x)[:failedAt])
+		return
+	}
+	likelyOnly := len(t.Corrections) > 0 && t.Corrections[0].Likely
+	seen := make(map[int]bool)
+	for _, c := range t.Corrections {
+		if likelyOnly && !c.Likely {
+			break
+		}
+		switch c.Kind {
+		case Transposition:
+			seen[c.Position], seen[c.Position+1] = true, true
+		case Substitution:
+			seen[c.Position] = true
+		}
+	}
+	for i := 1; i <= pos; i++ {
+		if seen[i] {
+			t.Suspects = append(t.Suspects, i)
+		}
+	}
+	return
+}
+
+// traceDamm lists the check characters of a V2 ID up to the first failing one
+func traceDamm(chars []TracedChar) (checks []DammCheck) {
+	interim := 0
+	slots := len(chars) - 6
+	started := slots == 0 || slots > 1 && (slots-1)%(v2GroupLength+1) == 0
+	for _, c := range chars {
+		interim = dammStep(interim, c.Value)
+		slot := c.Position - 7
+		final := slot == slots-1 && !started
+		if slot >= 0 && (isV2CheckSlot(slot) || final) {
+			checks = append(checks, DammCheck{c.Position, interim, final})
+			if interim != 0 {
+				return
+			}
+		}
+	}
+	return
+}
+
+// prefixSuspects determines the positions at which a different digit makes the invalid prefix plausible
+func prefixSuspects(prefix []rune) (suspects []int) {
+	last := len(prefix) - 1
+	if _, ok := customBase32Decode(prefix[last]); !ok || last == 0 {
+		return []int{last + 1}
+	}
+	for i := range prefix {
+		original := prefix[i]
+		for _, r := range customBase32Alphabet[:8] {
+			prefix[i] = r
+			if _, err, _ := Decode(string(prefix)); err == nil {
+				suspects = append(suspects, i+1)
+				break
+			}
+		}
+		prefix[i] = original
+	}
+	if len(suspects) == 0 {
+		suspects = []int{last + 1}
+	}
+	return
+}
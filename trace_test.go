@@ -0,0 +1,61 @@
+package ndocid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTrace(t *testing.T) {
+	assertSuspects := func(tr Trace, exp ...int) {
+		if !reflect.DeepEqual(tr.Suspects, exp) {
+			t.Errorf(`"%s" traced, got suspects %v but expected %v`, tr.Input, tr.Suspects, exp)
+		}
+	}
+
+	valid := DecodeTrace("94875JBZOD")
+	if valid.Err != nil || !valid.Complete || valid.Value != 1568577394 {
+		t.Errorf("trace result differs from decoding: %v", valid)
+	}
+	if len(valid.Chars) != 10 || valid.Chars[5].Char != 'J' || valid.Chars[5].Value != 16 || valid.Chars[5].Weight != 1 || valid.Chars[6].Weight != 3 {
+		t.Errorf("unexpected character mapping: %v", valid.Chars)
+	}
+	for _, p := range valid.Parity {
+		if !p.OK() {
+			t.Errorf("parity check %s unexpectedly not OK", p.Name)
+		}
+	}
+	if !valid.Master.OK() || valid.Master.Sum != 203 {
+		t.Errorf("unexpected master check: %v", valid.Master)
+	}
+	assertSuspects(valid)
+
+	partial := DecodeTrace("948")
+	if partial.Err != nil || partial.Complete || !partial.Parity[0].OK() || partial.Parity[1].Evaluated || partial.Master.Evaluated {
+		t.Errorf("unexpected partial trace: %v", partial)
+	}
+
+	parity := DecodeTrace("92332")
+	if !parity.Parity[0].OK() || parity.Parity[1].OK() || !parity.Parity[1].Evaluated || parity.Parity[2].Evaluated {
+		t.Errorf("unexpected parity checks: %v", parity.Parity)
+	}
+	if parity.Err.(*DecodeError).Position != 4 {
+		t.Errorf("unexpected error position: %s", parity.Err)
+	}
+	assertSuspects(parity, 1, 4)
+
+	master := DecodeTrace("94785JBZOD")
+	if master.Master.OK() || master.Master.Remainder != 27 {
+		t.Errorf("unexpected master check: %v", master.Master)
+	}
+	assertSuspects(master, 3, 4)
+
+	assertSuspects(DecodeTrace("2222!X"), 5)
+	assertSuspects(DecodeTrace("22A"), 3)
+	assertSuspects(DecodeTrace("22222X!"), 7)
+
+	v2 := DecodeTrace("22222Z2222E33")
+	if v2.Version != V2 || v2.Master.Evaluated || len(v2.Damm) != 2 || !v2.Damm[0].OK() || v2.Damm[1].OK() || !v2.Damm[1].Final {
+		t.Errorf("unexpected version 2 trace: %v", v2)
+	}
+	assertSuspects(DecodeTrace("72639Z77DL8"), 9, 10)
+}
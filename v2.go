@@ -0,0 +1,168 @@
+package ndocid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Version identifies the layout of an ID
+type Version int
+
+const (
+	// V1 IDs carry three parity bits in the leading check character and a single mod-29 master check character
+	V1 Version = 1
+	// V2 IDs share the leading part with V1 IDs but are marked by a Z in position 6, followed by the
+	// variable part interleaved with check characters after every 4 symbols and a final check character.
+	// The check characters are calculated using the Damm algorithm over GF(32) which detects
+	// all single substitutions, adjacent and jump transpositions as well as twin errors.
+	// Only the marker itself is not covered: one or two substitutes turn the ID into a valid V1 ID.
+	V2 Version = 2
+)
+
+const (
+	v2Marker      = 31 // Z, never emitted as master check character of a V1 ID
+	v2GroupLength = 4  // symbols of the variable part between interleaved check characters
+	maxV2Length   = 20 // length of the longest V2 ID, i.e. the encoding of the maximum 64 bit value
+)
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// Encode encodes the given number in the layout of the version
+func (v Version) Encode(x uint64) string {
+	switch v {
+	case V1:
+		return EncodeUint64(x)
+	case V2:
+		verboseLineOut("Received numeric input: %d", x)
+		return encodeV2(x)
+	}
+	panic(fmt.Sprintf("ndocid: unknown version %d", int(v)))
+}
+
+// VersionOf reports the layout of the given ID judging by its marker.
+// IDs shorter than 6 characters are reported as V1 because both versions share the first five characters.
+func VersionOf(id string) Version {
+	if runes := []rune(id); len(runes) >= 6 {
+		if d, ok := customBase32Decode(runes[5]); ok && d == v2Marker {
+			return V2
+		}
+	}
+	return V1
+}
+
+// Convert re-encodes a valid complete ID in the layout of the given version
+func Convert(id string, v Version) (converted string, err error) {
+	x, err, complete := Decode(id)
+	if err != nil {
+		return
+	}
+	if !complete {
+		err = fmt.Errorf("ID incomplete")
+		return
+	}
+	converted = v.Encode(x)
+	return
+}
+
+// gf32Double multiplies an element of GF(32), represented as polynomial over GF(2) modulo x^5 + x^2 + 1, by x
+func gf32Double(i int) int {
+	i <<= 1
+	if i&0b100000 != 0 {
+		i ^= 0b100101
+	}
+	return i
+}
+
+// dammStep is the quasigroup operation of the Damm algorithm: interim ∘ d := 2·interim ⊕ d
+func dammStep(interim int, d int) int {
+	return gf32Double(interim) ^ d
+}
+
+// dammCheck calculates the character which brings the given interim digit back to 0
+func dammCheck(interim int) int {
+	return gf32Double(interim)
+}
+
+// isV2CheckSlot tells whether the slot with the given 0-based index after the marker holds an interleaved check character
+func isV2CheckSlot(slot int) bool {
+	return slot%(v2GroupLength+1) == v2GroupLength
+}
+
+func encodeV2(x uint64) (r string) {
+	fp := fixedPart(x)
+	syms := append(make([]int, 0, 20), fp[:]...)
+	syms = append(syms, v2Marker)
+
+	interim := 0
+	for _, d := range syms {
+		interim = dammStep(interim, d)
+	}
+	var vp, checks []int
+	appendSym := func(d int) {
+		syms = append(syms, d)
+		interim = dammStep(interim, d)
+	}
+	for vr := x >> 12; vr > 0; vr >>= 5 {
+		appendSym(int(vr & 0b11111))
+		vp = append(vp, int(vr&0b11111))
+		if isV2CheckSlot(len(syms) - 6) {
+			checks = append(checks, dammCheck(interim))
+			appendSym(dammCheck(interim))
+		}
+	}
+	if len(vp) == 0 || len(vp)%v2GroupLength != 0 {
+		checks = append(checks, dammCheck(interim))
+		appendSym(dammCheck(interim))
+	}
+
+	var acc strings.Builder
+	for _, d := range syms {
+		acc.WriteRune(customBase32Encode(d))
+	}
+	r = acc.String()
+
+	if Verbose {
+		verboseLineOut("Encoding %d in version 2 layout:", x)
+		verboseLineOut("  Leading [F]ixed [P]art FP as in version 1: %v", fp)
+		verboseLineOut("  Version marker after FP: %c", customBase32Encode(v2Marker))
+		verboseLineOut("  Trailing [V]ariable [P]art VP := next 5 LSB each: %v", vp)
+		verboseLineOut("  [C]heck characters using Damm algorithm over GF(32):")
+		verboseLineOut("    interim := 2 * interim XOR next symbol (multiplication modulo x^5 + x^2 + 1)")
+		verboseLineOut("    C := 2 * interim, inserted after every %d symbols of VP and at the end", v2GroupLength)
+		verboseLineOut("    < C1 C2 ...: %v", checks)
+		verboseLineOut("  Encoding using custom Base32 mapping...")
+		verboseLineOut("    Alphabet used: %s", customBase32Alphabet)
+		verboseLineOut("< Result: %s", r)
+	}
+	return
+}
+
+// decodeV2 continues decoding the mapped symbols of a V2 ID after its leading fixed part r has been checked
+func decodeV2(id []uint64, r uint64) (uint64, error, bool) {
+	interim := 0
+	for _, d := range id[:6] {
+		interim = dammStep(interim, int(d))
+	}
+	slots := len(id) - 6
+	// the last symbol is the final check character unless a new group of the variable part has just been started
+	started := slots == 0 || slots > 1 && (slots-1)%(v2GroupLength+1) == 0
+	lastCheck := 6
+	data := 0
+	for slot := 0; slot < slots; slot++ {
+		d := int(id[6+slot])
+		interim = dammStep(interim, d)
+		switch {
+		case isV2CheckSlot(slot) || slot == slots-1 && !started:
+			if interim != 0 {
+				return 0, &DecodeError{lastCheck + 1, fmt.Sprintf("ID invalid starting after position %d", lastCheck)}, false
+			}
+			lastCheck = 7 + slot
+		default:
+			r |= uint64(d) << (12 + data*5)
+			data++
+		}
+	}
+	return r, nil, !started
+}
@@ -0,0 +1,141 @@
+package ndocid
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeV2(t *testing.T) {
+	assertEncoded := func(input uint64, exp string) {
+		act := V2.Encode(input)
+		if act != exp {
+			t.Errorf(`%d encoded, got "%s" but expected "%s"`, input, act, exp)
+		}
+	}
+
+	coverVerboseButHideIt()
+
+	assertEncoded(1552572000, "72639Z77LD8")                     //Pi-Day 2019, 3pm in Germany
+	assertEncoded(0, "22222ZV")                                  //Minimum
+	assertEncoded(4096, "22222Z3K")                              //Smallest value with variable part
+	assertEncoded(1<<32, "22222Z2222E34")                        //Exactly one full group
+	assertEncoded(0xFFFF_FFFF_FFFF_FFFF, "49999ZZZZZWZZZZFZZ5P") //Maximum
+
+	if V1.Encode(1552572000) != "72639D77LD" {
+		t.Error("version 1 encoding differs")
+	}
+}
+
+func TestDecodeV2(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 5000; i++ {
+		x := rng.Uint64() >> uint(rng.Intn(64))
+		id := V2.Encode(x)
+		act, err, complete := Decode(id)
+		if err != nil || !complete || act != x {
+			t.Fatalf(`"%s" decoded to %d (error: %v, complete: %t) but expected %d`, id, act, err, complete, x)
+		}
+		if _, err, complete := Decode(id[:5]); err != nil || complete {
+			t.Fatalf(`leading part of "%s" not plausible`, id)
+		}
+	}
+
+	assertDecodingFailure := func(input string) {
+		_, err, complete := Decode(input)
+		if err == nil {
+			t.Errorf(`no error on attempt of decoding "%s"`, input)
+		}
+		if complete {
+			t.Errorf(`"%s" unexpectedly complete`, input)
+		}
+	}
+	assertDecodingFailure("72639Z77LD9")
+	assertDecodingFailure("72639Z77DL8")
+	assertDecodingFailure("22222Z2223E34")
+	assertDecodingFailure("22222Z2222F34")
+
+	if _, err, complete := Decode("22222Z2222E3"); err != nil || complete {
+		t.Error("new group not considered partial")
+	}
+	if _, err, complete := Decode("22222Z2222E33"); err == nil || complete {
+		t.Error("failing final check not detected")
+	}
+	if _, err, complete := Decode("22222Z2222E"); err != nil || !complete {
+		t.Error("group ending with interleaved check not accepted")
+	}
+	if _, err, complete := Decode("22222Z"); err != nil || complete {
+		t.Error("marker only not considered partial")
+	}
+}
+
+func TestV2ErrorDetection(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	undetected := func(original []rune, modified []rune) bool {
+		_, err, complete := Decode(string(modified))
+		return err == nil && complete && string(original) != string(modified)
+	}
+	for i := 0; i < 300; i++ {
+		id := []rune(V2.Encode(rng.Uint64() >> uint(rng.Intn(64))))
+		for p := range id {
+			if p == 5 {
+				//substituting the marker switches to version 1 checks, one or two characters pass them
+				passing := 0
+				for _, r := range customBase32Alphabet {
+					m := append([]rune{}, id...)
+					m[p] = r
+					if undetected(id, m) {
+						passing++
+					}
+				}
+				if passing > 2 {
+					t.Fatalf(`%d substitutions of the marker of "%s" not detected`, passing, string(id))
+				}
+				continue
+			}
+			for _, r := range customBase32Alphabet {
+				m := append([]rune{}, id...)
+				m[p] = r
+				if undetected(id, m) {
+					t.Fatalf(`substitution "%s" of "%s" not detected`, string(m), string(id))
+				}
+			}
+			for _, distance := range []int{1, 2} {
+				q := p + distance
+				if q >= len(id) || p <= 5 && q >= 5 && !(p == 4 && q == 5) {
+					continue
+				}
+				m := append([]rune{}, id...)
+				m[p], m[q] = m[q], m[p]
+				if undetected(id, m) {
+					t.Fatalf(`transposition "%s" of "%s" not detected`, string(m), string(id))
+				}
+				if distance == 1 && id[p] == id[q] && p > 5 {
+					for _, r := range customBase32Alphabet {
+						m[p], m[q] = r, r
+						if undetected(id, m) {
+							t.Fatalf(`twin error "%s" of "%s" not detected`, string(m), string(id))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestVersionAndConversion(t *testing.T) {
+	if VersionOf("72639Z77LD8") != V2 || VersionOf("72639D77LD") != V1 || VersionOf("72639") != V1 {
+		t.Error("version misidentified")
+	}
+	if c, err := Convert("72639D77LD", V2); err != nil || c != "72639Z77LD8" {
+		t.Errorf("conversion to version 2 failed: %s %v", c, err)
+	}
+	if c, err := Convert("72639Z77LD8", V1); err != nil || c != "72639D77LD" {
+		t.Errorf("conversion to version 1 failed: %s %v", c, err)
+	}
+	if _, err := Convert("72639", V2); err == nil {
+		t.Error("partial ID converted")
+	}
+	if _, err := Convert("72639Z77LD9", V1); err == nil {
+		t.Error("invalid ID converted")
+	}
+}
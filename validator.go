@@ -0,0 +1,209 @@
+package ndocid
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// maxLength is the length of the longest ID, i.e. the encoding of the maximum 64 bit value
+const maxLength = 17
+
+// MaxIDLength is the length of the longest ID of any layout, longer input cannot be made valid by a single edit
+const MaxIDLength = maxV2Length
+
+// ValidationStatus classifies an input as reported by Decode
+type ValidationStatus int
+
+const (
+	Partial  ValidationStatus = iota // plausible beginning of an ID
+	Complete                         // valid full ID
+	Invalid                          // rejected by Decode
+)
+
+func (s ValidationStatus) String() string {
+	switch s {
+	case Partial:
+		return "PARTIAL"
+	case Complete:
+		return "OK"
+	case Invalid:
+		return "INVALID"
+	}
+	return "UNKNOWN"
+}
+
+// ValidationState describes the input of a Validator after the latest keystroke
+type ValidationState struct {
+	Status ValidationStatus
+	Length int
+	Value  uint64 // decoded value if Status is Complete
+	Err    error  // the error returned by Decode if Status is Invalid
+	// Offending is the 1-based position of the first offending character if Status is Invalid
+	Offending int
+	// Completable reports whether the input is or can still be extended to a valid ID of at most 64 bits.
+	// An invalid input may still be completable if only the master check failed.
+	Completable bool
+}
+
+// Validator checks an ID character by character as it is typed, each keystroke costs constant time.
+// Its states are consistent with the result of Decode for the respective input.
+// The zero value is an empty Validator ready to use.
+type Validator struct {
+	frames []validatorFrame
+}
+
+type validatorFrame struct {
+	char      rune
+	value     uint64
+	fc        int
+	check     int
+	interim   int          // Damm check digit of V2 IDs
+	v2        bool         // the V2 marker has been read
+	lastCheck int          // position of the last interleaved check character of V2 IDs
+	lost      bool         // non-zero bits beyond 64 bits were dropped
+	err       *DecodeError // error which cannot be fixed by appending characters
+}
+
+// Push appends a character to the input
+func (v *Validator) Push(char rune) ValidationState {
+	var f validatorFrame
+	if len(v.frames) > 0 {
+		f = v.frames[len(v.frames)-1]
+	}
+	f.char = char
+	pos := len(v.frames) + 1
+	if f.err == nil {
+		f.err = f.push(char, pos)
+	}
+	v.frames = append(v.frames, f)
+	return v.State()
+}
+
+func (f *validatorFrame) push(char rune, pos int) *DecodeError {
+	d, mapped := customBase32Decode(char)
+	if !mapped {
+		return &DecodeError{pos, fmt.Sprintf("Bad character in position %d: %c (%U)", pos, char, char)}
+	}
+	if pos <= 5 && d > 7 {
+		return &DecodeError{pos, fmt.Sprintf("Non-[2,9]-numeric character in position %d: %c (%U)", pos, char, char)}
+	}
+	f.check += (1 + pos%2*2) * d
+	f.interim = dammStep(f.interim, d)
+	dataIndex := pos - 7
+	switch {
+	case pos == 1:
+		f.fc = d
+	case pos <= 5:
+		f.value |= uint64(d) << ((pos - 2) * 3)
+		if pos >= 3 && (bits.OnesCount64(f.value)+f.fc>>(5-pos)&0b1)%2 == 1 {
+			return &DecodeError{pos, fmt.Sprintf("ID invalid starting at position %d", pos)}
+		}
+	case pos == 6:
+		f.v2 = d == v2Marker
+		f.lastCheck = 6
+		return nil
+	case f.v2:
+		slot := pos - 7
+		if isV2CheckSlot(slot) {
+			if f.interim != 0 {
+				return &DecodeError{f.lastCheck + 1, fmt.Sprintf("ID invalid starting after position %d", f.lastCheck)}
+			}
+			f.lastCheck = pos
+			return nil
+		}
+		dataIndex = slot - slot/(v2GroupLength+1)
+	}
+	if pos >= 7 {
+		shift := 12 + dataIndex*5
+		if shift >= 64 {
+			f.lost = f.lost || d > 0
+			return nil
+		}
+		f.lost = f.lost || bits.Len64(uint64(d)) > 64-shift
+		f.value |= uint64(d) << shift
+	}
+	return nil
+}
+
+// Pop removes the last character from the input, popping an empty Validator has no effect
+func (v *Validator) Pop() ValidationState {
+	if len(v.frames) > 0 {
+		v.frames = v.frames[:len(v.frames)-1]
+	}
+	return v.State()
+}
+
+// Reset empties the input
+func (v *Validator) Reset() {
+	v.frames = v.frames[:0]
+}
+
+// Input returns the characters pushed so far
+func (v *Validator) Input() string {
+	chars := make([]rune, len(v.frames))
+	for i, f := range v.frames {
+		chars[i] = f.char
+	}
+	return string(chars)
+}
+
+// State reports the status of the current input
+func (v *Validator) State() (s ValidationState) {
+	s.Length = len(v.frames)
+	if s.Length == 0 {
+		s.Completable = true
+		return
+	}
+	f := v.frames[s.Length-1]
+	switch {
+	case f.err != nil:
+		s.Status, s.Err, s.Offending = Invalid, f.err, f.err.Position
+		return
+	case s.Length < 6:
+		s.Status, s.Completable = Partial, true
+		return
+	case f.v2:
+		v.stateV2(&s)
+		return
+	case f.check%29 != 0:
+		s.Status, s.Offending = Invalid, 6
+		s.Err = &DecodeError{6, "ID invalid starting after position 5"}
+	default:
+		s.Status, s.Value = Complete, f.value
+	}
+	s.Completable = !f.lost && (s.Status == Complete || v.completableByAppending(f.check))
+	return
+}
+
+func (v *Validator) stateV2(s *ValidationState) {
+	f := v.frames[s.Length-1]
+	slot := s.Length - 7
+	switch {
+	case slot < 0 || slot > 0 && slot%(v2GroupLength+1) == 0:
+		s.Status, s.Completable = Partial, !f.lost
+	case isV2CheckSlot(slot):
+		s.Status, s.Value, s.Completable = Complete, f.value, !f.lost
+	case f.interim == 0:
+		//the last character is the final check character and holds no data
+		prev := v.frames[s.Length-2]
+		s.Status, s.Value, s.Completable = Complete, prev.value, !prev.lost
+	default:
+		s.Status, s.Offending, s.Completable = Invalid, f.lastCheck+1, !f.lost
+		s.Err = &DecodeError{f.lastCheck + 1, fmt.Sprintf("ID invalid starting after position %d", f.lastCheck)}
+	}
+}
+
+// completableByAppending reports whether a single further character can fix the master check
+func (v *Validator) completableByAppending(check int) bool {
+	pos := len(v.frames) + 1
+	if pos > maxLength {
+		return false
+	}
+	shift := 12 + (pos-7)*5
+	for d := 0; d < 32 && bits.Len64(uint64(d)) <= 64-shift; d++ {
+		if (check+(1+pos%2*2)*d)%29 == 0 {
+			return true
+		}
+	}
+	return false
+}
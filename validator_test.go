@@ -0,0 +1,94 @@
+package ndocid
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestValidatorConsistency(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []rune(customBase32Alphabet + "SG10!")
+	var v Validator
+	assertConsistent := func(s ValidationState) {
+		input := v.Input()
+		value, err, complete := Decode(input)
+		switch {
+		case err != nil:
+			if s.Status != Invalid || s.Err.Error() != err.Error() || s.Offending != err.(*DecodeError).Position {
+				t.Fatalf(`"%s" decoding failed with "%s" but validator reported %v`, input, err, s)
+			}
+		case complete:
+			if s.Status != Complete || s.Value != value {
+				t.Fatalf(`"%s" decoded to %d but validator reported %v`, input, value, s)
+			}
+		default:
+			if s.Status != Partial || !s.Completable {
+				t.Fatalf(`"%s" decoded as partial but validator reported %v`, input, s)
+			}
+		}
+		if s.Length != len([]rune(input)) {
+			t.Fatalf(`"%s" has length %d but validator reported %d`, input, len([]rune(input)), s.Length)
+		}
+	}
+
+	for i := 0; i < 2000; i++ {
+		x := rng.Uint64() >> uint(rng.Intn(64))
+		v.Reset()
+		for _, char := range Version(1 + i%2).Encode(x) {
+			assertConsistent(v.Push(char))
+		}
+		if s := v.State(); s.Status != Complete || s.Value != x {
+			t.Fatalf("encoding of %d not validated: %v", x, s)
+		}
+		for v.State().Length > 0 {
+			if rng.Intn(3) == 0 {
+				assertConsistent(v.Push(alphabet[rng.Intn(len(alphabet))]))
+			}
+			assertConsistent(v.Pop())
+		}
+	}
+}
+
+func TestValidatorStates(t *testing.T) {
+	assertState := func(input string, status ValidationStatus, offending int, completable bool) {
+		var v Validator
+		for _, char := range input {
+			v.Push(char)
+		}
+		s := v.State()
+		if s.Status != status || s.Offending != offending || s.Completable != completable {
+			t.Errorf(`"%s" validated as %s at %d (completable: %t) but expected %s at %d (completable: %t)`, input, s.Status, s.Offending, s.Completable, status, offending, completable)
+		}
+	}
+
+	assertState("", Partial, 0, true)
+	assertState("684", Partial, 0, true)
+	assertState("68495LTTOD", Complete, 0, true)
+	assertState("68495LTTO", Invalid, 6, true)
+	assertState("68495LTT!D", Invalid, 9, false)
+	assertState("68495LTT!", Invalid, 9, false)
+	assertState("22332", Invalid, 3, false)
+	assertState("2222A", Invalid, 5, false)
+	assertState("499997ZZZZZZZZZZ5", Complete, 0, true)
+	assertState("499997ZZZZZZZZZZ", Invalid, 6, true)
+	assertState("499997ZZZZZZZZZZ6", Invalid, 6, false)
+	assertState("22222X22222222222", Complete, 0, true)
+	assertState("22222X22222222222Z", Invalid, 6, false)
+	assertState("22222X2222222222", Complete, 0, true)
+	assertState("22222X2222222222Z", Invalid, 6, false)
+	assertState("72639Z77LD8", Complete, 0, true)
+	assertState("72639Z77LD", Invalid, 7, true)
+	assertState("22222Z2222", Invalid, 7, true)
+	assertState("22222Z2223E", Invalid, 7, false)
+	assertState("22222Z2222E3", Partial, 0, true)
+	assertState("22222Z2222E", Complete, 0, true)
+	assertState("22222Z2222E33", Invalid, 12, true)
+	assertState("22222Z2222E2", Partial, 0, true)
+
+	var v Validator
+	v.Pop()
+	v.Push('6')
+	if s := v.Pop(); s.Length != 0 || s.Status != Partial {
+		t.Errorf("unexpected state after popping everything: %v", s)
+	}
+}
@@ -0,0 +1,139 @@
+package ndocid
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Wordlist holds the words of the word encoding, each standing for the byte of its index.
+// The first three letters of every word are unique.
+var Wordlist = [256]string{
+	"able", "actor", "agile", "album", "ample", "ankle", "arm", "aspen", "audio", "autumn", "axis", "baby",
+	"bake", "barn", "bed", "berry", "black", "book", "bracket", "bubble", "bulb", "butter", "cabin", "camel",
+	"cat", "cereal", "choir", "citrus", "coin", "coral", "cowboy", "cube", "cycle", "daisy", "dawn", "delta",
+	"desk", "dice", "disk", "dolphin", "door", "dragon", "duck", "dust", "eagle", "earth", "echo", "edge",
+	"eel", "egg", "elm", "ember", "emerald", "epic", "evening", "fabric", "family", "farm", "fence", "fiddle",
+	"finger", "foam", "folder", "fox", "fruit", "fur", "gadget", "garden", "gecko", "gem", "giant", "giraffe",
+	"gold", "grape", "green", "guitar", "gym", "hair", "hand", "hawk", "heart", "hen", "hill", "holiday",
+	"horse", "house", "hurdle", "hut", "ice", "icon", "igloo", "image", "inch", "index", "insect", "iron",
+	"island", "ivory", "ivy", "jacket", "jam", "jar", "jeans", "jelly", "jigsaw", "job", "joke", "journey",
+	"juice", "jump", "jury", "kayak", "kettle", "key", "kid", "king", "kit", "kiwi", "knee", "knife",
+	"koala", "label", "lake", "laptop", "lawn", "lemon", "leopard", "lime", "liquid", "llama", "lock", "lotus",
+	"lynx", "magnet", "maple", "mask", "medal", "menu", "metal", "milk", "mirror", "model", "moon", "mouse",
+	"museum", "nail", "napkin", "navy", "needle", "nest", "net", "nickel", "noodle", "nose", "nugget", "nurse",
+	"nut", "oak", "oasis", "octopus", "olive", "omelet", "opal", "orange", "orchid", "organ", "otter", "owl",
+	"oyster", "paddle", "paper", "pasta", "pencil", "pickle", "pillow", "pizza", "plum", "pony", "prism", "pumpkin",
+	"puzzle", "quail", "queen", "quilt", "rabbit", "radio", "rain", "raven", "reef", "ribbon", "ridge", "ring",
+	"robin", "rose", "ruby", "ruler", "saddle", "sand", "seal", "shell", "shoe", "skate", "snake", "soup",
+	"spoon", "star", "summer", "swan", "table", "tea", "tennis", "tiger", "timber", "tomato", "tooth", "tower",
+	"tractor", "tulip", "tuna", "twig", "uncle", "unicorn", "valley", "vanilla", "vase", "velvet", "violin", "visor",
+	"voice", "volcano", "vowel", "wagon", "walnut", "wave", "weasel", "whale", "wheat", "whistle", "willow", "window",
+	"wolf", "wood", "worm", "yacht", "yak", "yarn", "yellow", "yogurt", "yolk", "zebra", "zest", "zigzag",
+	"zinc", "zipper", "zone", "zoo",
+}
+
+// WordSeparator joins the words of encoded values
+const WordSeparator = "-"
+
+var wordIndex = func() map[string]byte {
+	index := make(map[string]byte, len(Wordlist))
+	for i, word := range Wordlist {
+		index[word[:3]] = byte(i)
+	}
+	return index
+}()
+
+// wordsCheck calculates the check byte over GF(256) which detects every substitution and
+// every transposition of two different words, the leading 1 makes the check depend on the number of words
+func wordsCheck(data []byte) byte {
+	h := 1
+	for _, b := range data {
+		h = gf256Double(h) ^ int(b)
+	}
+	return byte(gf256Double(h))
+}
+
+// gf256Double multiplies an element of GF(256), represented as polynomial over GF(2) modulo x^8 + x^4 + x^3 + x^2 + 1, by x
+func gf256Double(i int) int {
+	i <<= 1
+	if i&0x100 != 0 {
+		i ^= 0x11d
+	}
+	return i
+}
+
+// EncodeWords encodes the number as words for people to remember: its bytes starting with the most
+// significant one that is not zero, followed by a check word, e.g. "inch-metal-insect-island-audio"
+func EncodeWords(x uint64) string {
+	var data []byte
+	for shift := 56; shift >= 0; shift -= 8 {
+		if b := byte(x >> shift); b != 0 || len(data) > 0 || shift == 0 {
+			data = append(data, b)
+		}
+	}
+	words := make([]string, 0, len(data)+1)
+	for _, b := range append(data, wordsCheck(data)) {
+		words = append(words, Wordlist[b])
+	}
+	return strings.Join(words, WordSeparator)
+}
+
+// DecodeWords returns the number encoded as words. Words may be separated by hyphens, spaces or punctuation
+// and shortened to their first three letters, case does not matter.
+func DecodeWords(s string) (uint64, error) {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	var data []byte
+	for i, word := range words {
+		b, ok := wordIndex[firstRunes(word, 3)]
+		if !ok || len(word) < 3 || !strings.HasPrefix(Wordlist[b], word) {
+			return 0, &DecodeError{i + 1, fmt.Sprintf("Unknown word in position %d: %s", i+1, word)}
+		}
+		data = append(data, b)
+	}
+	switch {
+	case len(data) < 2:
+		return 0, &DecodeError{len(data) + 1, "At least two words expected"}
+	case len(data) > 9:
+		return 0, &DecodeError{len(data), "Too many words for a 64 bit number"}
+	case data[0] == 0 && len(data) > 2:
+		return 0, &DecodeError{1, fmt.Sprintf("Leading word %s not allowed", Wordlist[0])}
+	case wordsCheck(data[:len(data)-1]) != data[len(data)-1]:
+		return 0, &DecodeError{len(data), "Check word mismatch"}
+	}
+	var x uint64
+	for _, b := range data[:len(data)-1] {
+		x = x<<8 | uint64(b)
+	}
+	return x, nil
+}
+
+func firstRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// WordsFromID re-encodes a valid complete ID as words
+func WordsFromID(id string) (string, error) {
+	x, err, complete := Decode(id)
+	if err != nil {
+		return "", err
+	}
+	if !complete {
+		return "", fmt.Errorf("ID incomplete")
+	}
+	return EncodeWords(x), nil
+}
+
+// IDFromWords re-encodes valid words as ID in the layout of the given version
+func IDFromWords(words string, v Version) (string, error) {
+	x, err := DecodeWords(words)
+	if err != nil {
+		return "", err
+	}
+	return v.Encode(x), nil
+}
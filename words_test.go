@@ -0,0 +1,68 @@
+package ndocid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWordlist(t *testing.T) {
+	prefixes := make(map[string]bool)
+	for _, word := range Wordlist {
+		if len(word) < 3 || prefixes[word[:3]] {
+			t.Errorf("word %q too short or its first three letters are not unique", word)
+		}
+		prefixes[word[:3]] = true
+	}
+}
+
+func TestWords(t *testing.T) {
+	for _, x := range []uint64{0, 1, 255, 256, 1552572000, 1<<63 + 12345, ^uint64(0)} {
+		words := EncodeWords(x)
+		decoded, err := DecodeWords(words)
+		if err != nil || decoded != x {
+			t.Errorf("%d: %s decoded as %d (%v)", x, words, decoded, err)
+		}
+	}
+	if words := EncodeWords(1552572000); strings.Count(words, WordSeparator) != 4 {
+		t.Errorf("expected a timestamp to take 5 words but got %s", words)
+	}
+
+	words := EncodeWords(1552572000)
+	id, err := IDFromWords(words, V2)
+	if err != nil || id != "72639Z77LD8" {
+		t.Errorf("expected conversion to 72639Z77LD8 but got %s (%v)", id, err)
+	}
+	if back, err := WordsFromID("72639D77LD"); err != nil || back != words {
+		t.Errorf("expected %s but got %s (%v)", words, back, err)
+	}
+
+	//separators, case and abbreviations
+	abbreviated := ""
+	for i, w := range strings.Split(words, WordSeparator) {
+		if i > 0 {
+			abbreviated += ", "
+		}
+		abbreviated += string([]rune(w)[:3])
+	}
+	if x, err := DecodeWords(abbreviated); err != nil || x != 1552572000 {
+		t.Errorf("%s: decoded %d (%v)", abbreviated, x, err)
+	}
+
+	bad := func(s string, pos int) {
+		t.Helper()
+		_, err := DecodeWords(s)
+		if e, ok := err.(*DecodeError); !ok || e.Position != pos {
+			t.Errorf("%q: expected error at %d but got %v", s, pos, err)
+		}
+	}
+	w := strings.Split(words, WordSeparator)
+	bad(w[0]+" "+w[2]+" "+w[1]+" "+w[3]+" "+w[4], 5)
+	bad(w[0]+" "+w[1]+" "+w[2]+" "+w[3], 4)
+	bad(w[0]+" xylophone "+w[2], 2)
+	bad(w[0]+" "+w[1][:2]+" "+w[2], 2)
+	bad(w[0], 2)
+	bad(Wordlist[0]+" "+words, 1)
+	if _, err := WordsFromID("72639D77DL"); err == nil {
+		t.Error("expected invalid ID to be rejected")
+	}
+}